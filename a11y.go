@@ -0,0 +1,211 @@
+package mx
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// A11yIssue is an accessibility problem found while rendering.
+type A11yIssue struct {
+	Rule      string // one of the Rule constants
+	Tag       string // offending element
+	Message   string
+	Component string // function that rendered the element
+	Source    string // file:line of the element in Component
+	id        string // id of an unlabelled form control, which a later <label for> can fix
+}
+
+func (i *A11yIssue) Error() string {
+	return fmt.Sprintf("%s: <%s>: %s [%s] (%s)", i.Source, i.Tag, i.Message, i.Rule, i.Component)
+}
+
+// Accessibility rules
+const (
+	RuleImageAlt     = "image-alt"     // <img> without alternative text
+	RuleLabel        = "label"         // form control without a label
+	RuleButtonName   = "button-name"   // <button> without an accessible name
+	RuleHeadingOrder = "heading-order" // heading level skipped
+	RuleARIARole     = "aria-role"     // unknown role
+	RuleARIAAttr     = "aria-attr"     // unknown aria-* attribute
+	RuleHTMLLang     = "html-lang"     // <html> without lang
+)
+
+// WAI-ARIA 1.2 roles, excluding abstract ones
+var ariaRoles = map[string]bool{
+	"alert": true, "alertdialog": true, "application": true, "article": true,
+	"banner": true, "blockquote": true, "button": true, "caption": true,
+	"cell": true, "checkbox": true, "code": true, "columnheader": true,
+	"combobox": true, "complementary": true, "contentinfo": true,
+	"definition": true, "deletion": true, "dialog": true, "document": true,
+	"emphasis": true, "feed": true, "figure": true, "form": true,
+	"generic": true, "grid": true, "gridcell": true, "group": true,
+	"heading": true, "img": true, "insertion": true, "link": true, "list": true,
+	"listbox": true, "listitem": true, "log": true, "main": true,
+	"marquee": true, "math": true, "menu": true, "menubar": true,
+	"menuitem": true, "menuitemcheckbox": true, "menuitemradio": true,
+	"meter": true, "navigation": true, "none": true, "note": true,
+	"option": true, "paragraph": true, "presentation": true,
+	"progressbar": true, "radio": true, "radiogroup": true, "region": true,
+	"row": true, "rowgroup": true, "rowheader": true, "scrollbar": true,
+	"search": true, "searchbox": true, "separator": true, "slider": true,
+	"spinbutton": true, "status": true, "strong": true, "subscript": true,
+	"superscript": true, "switch": true, "tab": true, "table": true,
+	"tablist": true, "tabpanel": true, "term": true, "textbox": true,
+	"time": true, "timer": true, "toolbar": true, "tooltip": true,
+	"tree": true, "treegrid": true, "treeitem": true,
+}
+
+// Prefixes of the roles defined by the DPUB-ARIA and Graphics ARIA modules
+var ariaRolePrefixes = []string{"doc-", "graphics-"}
+
+// WAI-ARIA 1.2 states and properties
+var ariaAttrs = map[string]bool{
+	"aria-activedescendant": true, "aria-atomic": true, "aria-autocomplete": true,
+	"aria-braillelabel": true, "aria-brailleroledescription": true,
+	"aria-busy": true, "aria-checked": true, "aria-colcount": true,
+	"aria-colindex": true, "aria-colindextext": true, "aria-colspan": true,
+	"aria-controls": true, "aria-current": true, "aria-describedby": true,
+	"aria-description": true, "aria-details": true, "aria-disabled": true,
+	"aria-dropeffect": true, "aria-errormessage": true, "aria-expanded": true,
+	"aria-flowto": true, "aria-grabbed": true, "aria-haspopup": true,
+	"aria-hidden": true, "aria-invalid": true, "aria-keyshortcuts": true,
+	"aria-label": true, "aria-labelledby": true, "aria-level": true,
+	"aria-live": true, "aria-modal": true, "aria-multiline": true,
+	"aria-multiselectable": true, "aria-orientation": true, "aria-owns": true,
+	"aria-placeholder": true, "aria-posinset": true, "aria-pressed": true,
+	"aria-readonly": true, "aria-relevant": true, "aria-required": true,
+	"aria-roledescription": true, "aria-rowcount": true, "aria-rowindex": true,
+	"aria-rowindextext": true, "aria-rowspan": true, "aria-selected": true,
+	"aria-setsize": true, "aria-sort": true, "aria-valuemax": true,
+	"aria-valuemin": true, "aria-valuenow": true, "aria-valuetext": true,
+}
+
+// Input types that are labelled by their value or don't need a label
+var unlabelledInputTypes = map[string]bool{
+	"button": true, "hidden": true, "image": true, "reset": true, "submit": true,
+}
+
+// A11yIssues returns the accessibility issues found so far when Node.A11y is
+// set. Form controls are reported only if no <label for> rendered so far
+// points to them.
+func A11yIssues(n *Node) []*A11yIssue {
+	issues := []*A11yIssue{}
+	for _, i := range n.issues {
+		if i.id == "" || !n.labels[i.id] {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+// linting checks if elements must be checked for accessibility issues.
+func (n *Node) linting() bool {
+	return n.DevMode && n.A11y
+}
+
+// lint checks an element being opened. Elements that need an accessible name
+// from their content keep a pending issue in f until they get one.
+func (n *Node) lint(f *frame, attrs, source string) {
+	report := func(rule, format string, args ...any) *A11yIssue {
+		return &A11yIssue{
+			Rule:      rule,
+			Tag:       f.tag,
+			Message:   fmt.Sprintf(format, args...),
+			Component: f.component,
+			Source:    source,
+		}
+	}
+	values := map[string]string{}
+	for _, a := range parseAttrs(attrs) {
+		values[a.name] = html.UnescapeString(a.value)
+		if strings.HasPrefix(a.name, "aria-") && !ariaAttrs[a.name] {
+			n.issues = append(n.issues, report(RuleARIAAttr, "unknown attribute %s", a.name))
+		}
+	}
+	named := func(attrs ...string) bool {
+		for _, a := range append(attrs, "aria-label", "aria-labelledby", "title") {
+			if strings.TrimSpace(values[a]) != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if role, ok := values["role"]; ok {
+		for _, r := range strings.Fields(role) {
+			if !ariaRoles[r] && !hasPrefix(r, ariaRolePrefixes) {
+				n.issues = append(n.issues, report(RuleARIARole, "unknown role %q", r))
+			}
+		}
+	}
+
+	switch f.tag {
+	case "html":
+		if strings.TrimSpace(values["lang"]) == "" {
+			n.issues = append(n.issues, report(RuleHTMLLang, "missing lang attribute"))
+		}
+	case "img":
+		_, alt := values["alt"]
+		if !alt && !named() && values["role"] != "presentation" && values["role"] != "none" {
+			n.issues = append(n.issues, report(RuleImageAlt, "missing alt attribute"))
+		}
+		if values["alt"] != "" {
+			n.name()
+		}
+	case "input", "select", "textarea":
+		if typ := strings.ToLower(values["type"]); f.tag == "input" && unlabelledInputTypes[typ] {
+			if typ == "image" && !named("alt") {
+				n.issues = append(n.issues, report(RuleImageAlt, "missing alt attribute"))
+			}
+			break
+		}
+		if !named() && !n.ancestor("label", nil) {
+			issue := report(RuleLabel, "form control without a label")
+			issue.id = values["id"]
+			n.issues = append(n.issues, issue)
+		}
+	case "label":
+		if id := values["for"]; id != "" {
+			if n.labels == nil {
+				n.labels = map[string]bool{}
+			}
+			n.labels[id] = true
+		}
+	case "button":
+		if !named() {
+			f.unnamed = report(RuleButtonName, "button without an accessible name")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(f.tag[1] - '0')
+		if n.heading != 0 && level > n.heading+1 {
+			n.issues = append(n.issues, report(RuleHeadingOrder, "heading level skipped after <h%d>", n.heading))
+		}
+		n.heading = level
+	}
+}
+
+// name gives an accessible name to the open elements that lack one.
+func (n *Node) name() {
+	for i := range n.stack {
+		n.stack[i].unnamed = nil
+	}
+}
+
+// lintEnd reports the innermost open element if it ends without the
+// accessible name it needs.
+func (n *Node) lintEnd() {
+	if f := n.stack[len(n.stack)-1]; f.unnamed != nil {
+		n.issues = append(n.issues, f.unnamed)
+	}
+}
+
+// hasPrefix checks if s has any of the prefixes.
+func hasPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	a11yTestCase struct {
+		description string
+		component   func(*Node)
+		expected    []string // "rule tag: message"
+	}
+)
+
+func a11yLogo(n *Node) {
+	n.Img(M{"src": "/logo.png"})
+}
+
+func TestA11y(t *testing.T) {
+	testCases := []a11yTestCase{
+		{
+			description: "img without alt",
+			component:   func(n *Node) { n.Img(M{"src": "/a.png"}) },
+			expected:    []string{"image-alt img: missing alt attribute"},
+		},
+		{
+			description: "decorative img",
+			component:   func(n *Node) { n.Img(M{"src": "/a.png", "alt": ""}) },
+			expected:    []string{},
+		},
+		{
+			description: "input without label",
+			component:   func(n *Node) { n.Input(M{"name": "email"}) },
+			expected:    []string{"label input: form control without a label"},
+		},
+		{
+			description: "input in label",
+			component: func(n *Node) {
+				n.Label(nil, Text("Email"), func(n *Node) { n.Input(M{"name": "email"}) })
+			},
+			expected: []string{},
+		},
+		{
+			description: "input labelled by a later label",
+			component: func(n *Node) {
+				n.Input(M{"id": "email"})
+				n.Label(M{"for": "email"}, Text("Email"))
+			},
+			expected: []string{},
+		},
+		{
+			description: "hidden and submit inputs",
+			component: func(n *Node) {
+				n.Input(M{"type": "hidden", "name": "csrf"})
+				n.Input(M{"type": "submit"})
+			},
+			expected: []string{},
+		},
+		{
+			description: "select with aria-label",
+			component:   func(n *Node) { n.Select(M{"aria-label": "Country"}) },
+			expected:    []string{},
+		},
+		{
+			description: "empty button",
+			component: func(n *Node) {
+				n.Button(nil, func(n *Node) { n.Span(S(`class="icon"`), Text(" ")) })
+			},
+			expected: []string{"button-name button: button without an accessible name"},
+		},
+		{
+			description: "button named by nested text",
+			component: func(n *Node) {
+				n.Button(nil, func(n *Node) { n.Span(nil, Text("Save")) })
+			},
+			expected: []string{},
+		},
+		{
+			description: "button named by an image",
+			component: func(n *Node) {
+				n.Button(nil, func(n *Node) { n.Img(M{"src": "/x.svg", "alt": "Close"}) })
+			},
+			expected: []string{},
+		},
+		{
+			description: "skipped heading level",
+			component: func(n *Node) {
+				n.H1(nil, Text("Title"))
+				n.H3(nil, Text("Section"))
+				n.H2(nil, Text("Section"))
+			},
+			expected: []string{"heading-order h3: heading level skipped after <h1>"},
+		},
+		{
+			description: "unknown role and aria attribute",
+			component: func(n *Node) {
+				n.Div(M{"role": "buton", "aria-lable": "x"}, Text("ok"))
+				n.Section(M{"role": "doc-chapter region"}, nil)
+			},
+			expected: []string{
+				"aria-attr div: unknown attribute aria-lable",
+				`aria-role div: unknown role "buton"`,
+			},
+		},
+		{
+			description: "html without lang",
+			component:   func(n *Node) { n.HTML(nil) },
+			expected:    []string{"html-lang html: missing lang attribute"},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("checks %v", tc.description)
+		t.Run(name, func(t *testing.T) {
+			n := &Node{Writer: &strings.Builder{}, DevMode: true, A11y: true}
+			tc.component(n)
+			issues := []string{}
+			for _, i := range A11yIssues(n) {
+				issues = append(issues, fmt.Sprintf("%s %s: %s", i.Rule, i.Tag, i.Message))
+			}
+			assert.Equal(t, tc.expected, issues)
+		})
+	}
+
+	t.Run("reports the source", func(t *testing.T) {
+		n := &Node{Writer: &strings.Builder{}, DevMode: true, A11y: true}
+		a11yLogo(n)
+		issues := A11yIssues(n)
+		if assert.Len(t, issues, 1) {
+			assert.Equal(t, "mx.a11yLogo", issues[0].Component)
+			assert.Regexp(t, `a11y_test.go:20$`, issues[0].Source)
+			assert.Regexp(t, `a11y_test.go:20: <img>: missing alt attribute \[image-alt\] \(mx.a11yLogo\)$`, issues[0].Error())
+		}
+	})
+
+	t.Run("requires dev mode", func(t *testing.T) {
+		n := &Node{Writer: &strings.Builder{}, A11y: true}
+		a11yLogo(n)
+		assert.Empty(t, A11yIssues(n))
+	})
+}
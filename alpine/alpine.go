@@ -0,0 +1,173 @@
+// Package alpine provides typed helpers for Alpine.js directives. Go values are
+// encoded as JSON and attribute values are escaped by mx, so data with quotes
+// or markup can't break the attribute:
+//
+//	n.Div(alpine.Data(map[string]any{"open": false, "items": items}), func(n *mx.Node) {
+//		n.Button(alpine.On("click", "open = !open", alpine.Prevent), mx.Text("Toggle"))
+//		n.Ul(alpine.Show("open"), ...)
+//	})
+package alpine
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jlucasnsilva/mx"
+	"github.com/jlucasnsilva/mx/internal/duration"
+)
+
+// Modifier changes the behavior of a directive, e.g. alpine.Prevent in
+// x-on:submit.prevent.
+type Modifier string
+
+// Event modifiers
+const (
+	Prevent  Modifier = "prevent"
+	Stop     Modifier = "stop"
+	Outside  Modifier = "outside"
+	Window   Modifier = "window"
+	Document Modifier = "document"
+	Once     Modifier = "once"
+	Self     Modifier = "self"
+	Camel    Modifier = "camel"
+	Dot      Modifier = "dot"
+	Passive  Modifier = "passive"
+	Capture  Modifier = "capture"
+)
+
+// Model modifiers
+const (
+	Lazy    Modifier = "lazy"
+	Number  Modifier = "number"
+	Boolean Modifier = "boolean"
+	Fill    Modifier = "fill"
+)
+
+// Debounce waits d without new events before running the handler or
+// updating the model.
+func Debounce(d time.Duration) Modifier {
+	return "debounce." + Modifier(duration.Format(d))
+}
+
+// Throttle runs the handler at most once every d.
+func Throttle(d time.Duration) Modifier {
+	return "throttle." + Modifier(duration.Format(d))
+}
+
+// Key filters keyboard events by key, e.g. Key("enter") or Key("shift").
+func Key(name string) Modifier {
+	return Modifier(name)
+}
+
+// JSON encodes v as a JavaScript expression. It fails if v has values JSON
+// can't encode, like functions or channels.
+func JSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// MustJSON is like JSON but panics if v can't be encoded.
+func MustJSON(v any) string {
+	s, err := JSON(v)
+	if err != nil {
+		panic("alpine: " + err.Error())
+	}
+	return s
+}
+
+// Call returns a JavaScript call of fn with args encoded as JSON, e.g.
+// Call("load", "/api/items", 2) is `load("/api/items",2)`. It panics if an
+// argument can't be encoded.
+func Call(fn string, args ...any) string {
+	s := make([]string, len(args))
+	for i, a := range args {
+		s[i] = MustJSON(a)
+	}
+	return fn + "(" + strings.Join(s, ",") + ")"
+}
+
+// Data declares a component with v, encoded as JSON, as its state. It panics
+// if v can't be encoded.
+func Data(v any) mx.Attr { return mx.M{"x-data": MustJSON(v)} }
+
+// DataExpr declares a component with the state returned by a JavaScript
+// expression, e.g. "dropdown()".
+func DataExpr(expr string) mx.Attr { return mx.M{"x-data": expr} }
+
+// Init runs expr when the component is initialized.
+func Init(expr string) mx.Attr { return mx.M{"x-init": expr} }
+
+// Show toggles the element's visibility with expr.
+func Show(expr string, modifiers ...Modifier) mx.Attr {
+	return mx.M{directive("x-show", modifiers): expr}
+}
+
+// Bind sets the attribute name to the value of expr.
+func Bind(name, expr string, modifiers ...Modifier) mx.Attr {
+	return mx.M{directive("x-bind:"+name, modifiers): expr}
+}
+
+// Class sets classes with expr, e.g. "{ 'active': selected }".
+func Class(expr string) mx.Attr { return Bind("class", expr) }
+
+// On runs expr when the event is dispatched on the element.
+func On(event, expr string, modifiers ...Modifier) mx.Attr {
+	return mx.M{directive("x-on:"+event, modifiers): expr}
+}
+
+// Text sets the text of the element to expr.
+func Text(expr string) mx.Attr { return mx.M{"x-text": expr} }
+
+// HTML sets the inner HTML of the element to expr. The content isn't escaped.
+func HTML(expr string) mx.Attr { return mx.M{"x-html": expr} }
+
+// Model binds the value of the input to the property expr.
+func Model(expr string, modifiers ...Modifier) mx.Attr {
+	return mx.M{directive("x-model", modifiers): expr}
+}
+
+// Modelable exposes the property expr to x-model on the component.
+func Modelable(expr string) mx.Attr { return mx.M{"x-modelable": expr} }
+
+// For repeats the content of a <template> for each item of a list, e.g.
+// "item in items".
+func For(expr string) mx.Attr { return mx.M{"x-for": expr} }
+
+// If renders the content of a <template> when expr is true.
+func If(expr string) mx.Attr { return mx.M{"x-if": expr} }
+
+// Effect runs expr whenever its dependencies change.
+func Effect(expr string) mx.Attr { return mx.M{"x-effect": expr} }
+
+// Ref names the element so $refs can access it.
+func Ref(name string) mx.Attr { return mx.M{"x-ref": name} }
+
+// Teleport moves the content of a <template> to the element matching selector.
+func Teleport(selector string) mx.Attr { return mx.M{"x-teleport": selector} }
+
+// ID scopes the ids generated by $id for the names.
+func ID(names ...string) mx.Attr { return mx.M{"x-id": MustJSON(names)} }
+
+// Transition animates the element when x-show toggles it, e.g.
+// Transition(alpine.Modifier("opacity")).
+func Transition(modifiers ...Modifier) mx.Attr {
+	return mx.S(directive("x-transition", modifiers))
+}
+
+// Cloak hides the element until Alpine is initialized.
+func Cloak() mx.Attr { return mx.S("x-cloak") }
+
+// Ignore stops Alpine from initializing the element and its children.
+func Ignore() mx.Attr { return mx.S("x-ignore") }
+
+// directive appends modifiers to a directive name.
+func directive(name string, modifiers []Modifier) string {
+	for _, m := range modifiers {
+		name += "." + string(m)
+	}
+	return name
+}
@@ -0,0 +1,79 @@
+package alpine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	attrTestCase struct {
+		attr     mx.Attr
+		expected string
+	}
+)
+
+func TestAttrs(t *testing.T) {
+	testCases := []attrTestCase{
+		{attr: Data(map[string]any{"open": false}), expected: `x-data="{&#34;open&#34;:false}"`},
+		{attr: DataExpr("dropdown()"), expected: `x-data="dropdown()"`},
+		{attr: Init(Call("load", "/api/items", 2)), expected: `x-init="load(&#34;/api/items&#34;,2)"`},
+		{attr: Show("open"), expected: `x-show="open"`},
+		{attr: Show("open", Modifier("important")), expected: `x-show.important="open"`},
+		{attr: Bind("disabled", "!valid"), expected: `x-bind:disabled="!valid"`},
+		{attr: Class("{ 'active': selected }"), expected: `x-bind:class="{ &#39;active&#39;: selected }"`},
+		{attr: On("click", "open = !open"), expected: `x-on:click="open = !open"`},
+		{attr: On("submit", "save()", Prevent), expected: `x-on:submit.prevent="save()"`},
+		{attr: On("click", "open = false", Outside, Window), expected: `x-on:click.outside.window="open = false"`},
+		{attr: On("keyup", "send()", Key("enter"), Debounce(300*time.Millisecond)), expected: `x-on:keyup.enter.debounce.300ms="send()"`},
+		{attr: On("scroll", "update()", Throttle(time.Second), Passive), expected: `x-on:scroll.throttle.1s.passive="update()"`},
+		{attr: Model("qty", Lazy, Number), expected: `x-model.lazy.number="qty"`},
+		{attr: Text("count"), expected: `x-text="count"`},
+		{attr: HTML("content"), expected: `x-html="content"`},
+		{attr: For("item in items"), expected: `x-for="item in items"`},
+		{attr: If("open"), expected: `x-if="open"`},
+		{attr: Ref("input"), expected: `x-ref="input"`},
+		{attr: Teleport("body"), expected: `x-teleport="body"`},
+		{attr: ID("tab", "panel"), expected: `x-id="[&#34;tab&#34;,&#34;panel&#34;]"`},
+		{attr: Effect("console.log(count)"), expected: `x-effect="console.log(count)"`},
+		{attr: Modelable("value"), expected: `x-modelable="value"`},
+		{attr: Transition(), expected: `x-transition`},
+		{attr: Transition(Modifier("opacity"), Modifier("duration.500ms")), expected: `x-transition.opacity.duration.500ms`},
+		{attr: Cloak(), expected: `x-cloak`},
+		{attr: Ignore(), expected: `x-ignore`},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders '%v'", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.attr.Attributes())
+		})
+	}
+}
+
+func TestData(t *testing.T) {
+	t.Run("survives HTML parsing", func(t *testing.T) {
+		data := map[string]any{"title": `He said "<b>hi</b>" & left`, "tags": []string{"a'b"}}
+		b := &strings.Builder{}
+		(&mx.Node{Writer: b}).Div(Data(data), nil)
+
+		doc, err := html.Parse(strings.NewReader(b.String()))
+		assert.NoError(t, err)
+		div := doc.FirstChild.LastChild.FirstChild
+		assert.Equal(t, "x-data", div.Attr[0].Key)
+		assert.Equal(t, MustJSON(data), div.Attr[0].Val)
+		assert.Equal(t, `{"tags":["a'b"],"title":"He said \"\u003cb\u003ehi\u003c/b\u003e\" \u0026 left"}`, div.Attr[0].Val)
+	})
+
+	t.Run("fails on values JSON can't encode", func(t *testing.T) {
+		_, err := JSON(func() {})
+		assert.EqualError(t, err, "json: unsupported type: func()")
+		assert.PanicsWithValue(t, "alpine: json: unsupported type: func()", func() { Data(func() {}) })
+	})
+}
@@ -0,0 +1,157 @@
+// Package assets fingerprints static files: each file is served under a name
+// with a hash of its content, e.g. app.3f9a1c2b.css, so it can be cached
+// forever, and the src and href attributes rendered by mx that point at the
+// files are rewritten to those names. With SRI, scripts and stylesheets also
+// get Subresource Integrity hashes, for the files and for external URLs listed
+// in a lockfile.
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	files, _ := fs.Sub(static, "static")
+//	m, err := assets.New(files, "/static/")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	m.CDN = "https://cdn.example.com" // optional
+//	http.Handle("/static/", m.Handler())
+//
+//	node := &mx.Node{Writer: w, Interceptors: []mx.Interceptor{m.Interceptor()}}
+//	node.Link(mx.S(`rel="stylesheet" href="/static/app.css"`)) // href="https://cdn.example.com/static/app.3f9a1c2b.css"
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+// Manifest maps the files of a file system to their fingerprinted names.
+type Manifest struct {
+	CDN    string            // origin that serves the files, e.g. "https://cdn.example.com"; empty to serve them from the same site
+	SRI    bool              // makes Interceptor add integrity and crossorigin to scripts and stylesheets
+	Lock   Lock              // integrity of external files, for SRI
+	fsys   fs.FS             // the files
+	prefix string            // URL path of the files, ending with "/"
+	names  map[string]string // fingerprinted name of each file
+	files  map[string]string // file of each fingerprinted name
+	sums   map[string]string // integrity of each file
+}
+
+// Length of the hash in fingerprinted names, in hex digits
+const hashLength = 8
+
+// Attributes that hold a URL
+var urlAttrs = map[string]bool{"src": true, "href": true, "poster": true}
+
+// New hashes the files of fsys, which are served under prefix, e.g. "/static/".
+func New(fsys fs.FS, prefix string) (*Manifest, error) {
+	m := &Manifest{
+		fsys:   fsys,
+		prefix: strings.TrimSuffix(prefix, "/") + "/",
+		names:  map[string]string{},
+		files:  map[string]string{},
+		sums:   map[string]string{},
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hashed := fingerprint(name, hex.EncodeToString(sum[:])[:hashLength])
+		m.names[name] = hashed
+		m.files[hashed] = name
+		m.sums[name] = Integrity(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// URL returns the URL of a file, e.g. "app.css" or "/static/app.css". URLs
+// that aren't files of the manifest are returned unchanged.
+func (m *Manifest) URL(name string) string {
+	rest := ""
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+	hashed, ok := m.names[strings.TrimPrefix(name, m.prefix)]
+	if !ok {
+		return name + rest
+	}
+	return m.CDN + m.prefix + hashed + rest
+}
+
+// Interceptor rewrites src, href, poster and srcset attributes that point at
+// files of the manifest. With SRI, it also adds integrity and crossorigin to
+// the scripts and stylesheets whose integrity is known.
+func (m *Manifest) Interceptor() mx.Interceptor {
+	return mx.Interceptor{
+		Attrs: func(tag string, attrs []mx.Attribute) []mx.Attribute {
+			for i, a := range attrs {
+				switch {
+				case urlAttrs[a.Name] && strings.HasPrefix(a.Value, m.prefix):
+					attrs[i].Value = m.URL(a.Value)
+				case a.Name == "srcset":
+					attrs[i].Value = m.srcset(a.Value)
+				}
+			}
+			if m.SRI {
+				attrs = m.addIntegrity(tag, attrs)
+			}
+			return attrs
+		},
+	}
+}
+
+// Handler serves the files under the prefix. Fingerprinted names are cached
+// forever; the original names are served too, revalidated on each request.
+func (m *Manifest) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutPrefix(r.URL.Path, m.prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if file, ok := m.files[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			name = file
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		http.ServeFileFS(w, r, m.fsys, name)
+	})
+}
+
+// srcset rewrites the URLs of a srcset attribute, e.g. "/a.png 1x, /b.png 2x".
+func (m *Manifest) srcset(s string) string {
+	candidates := strings.Split(s, ",")
+	for i, c := range candidates {
+		fields := strings.Fields(c)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], m.prefix) {
+			fields[0] = m.URL(fields[0])
+		}
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// fingerprint inserts hash before the extension of name.
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		ext = "" // dotfiles, e.g. ".well-known"
+	}
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
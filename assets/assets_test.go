@@ -0,0 +1,107 @@
+package assets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	urlTestCase struct {
+		name     string
+		cdn      string
+		expected string
+	}
+
+	serveTestCase struct {
+		path         string
+		status       int
+		body         string
+		cacheControl string
+	}
+)
+
+var files = fstest.MapFS{
+	"app.css":         {Data: []byte("body{}")},
+	"js/app.min.js":   {Data: []byte("go()")},
+	"img/logo.png":    {Data: []byte("png")},
+	"img/logo@2x.png": {Data: []byte("png2x")},
+	"LICENSE":         {Data: []byte("MIT")},
+}
+
+func TestURL(t *testing.T) {
+	testCases := []urlTestCase{
+		{name: "app.css", expected: "/static/app.7c98040a.css"},
+		{name: "/static/app.css", expected: "/static/app.7c98040a.css"},
+		{name: "/static/app.css?v=1#x", expected: "/static/app.7c98040a.css?v=1#x"},
+		{name: "js/app.min.js", expected: "/static/js/app.min.e4a62ff8.js"},
+		{name: "LICENSE", expected: "/static/LICENSE.e5dcffe8"},
+		{name: "app.css", cdn: "https://cdn.example.com", expected: "https://cdn.example.com/static/app.7c98040a.css"},
+		{name: "/static/missing.css", expected: "/static/missing.css"},
+		{name: "https://example.com/app.css", expected: "https://example.com/app.css"},
+	}
+
+	m, err := New(files, "/static")
+	assert.NoError(t, err)
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("maps '%v' with CDN '%v'", tc.name, tc.cdn)
+		t.Run(name, func(t *testing.T) {
+			m.CDN = tc.cdn
+			assert.Equal(t, tc.expected, m.URL(tc.name))
+		})
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	t.Run("rewrites asset URLs", func(t *testing.T) {
+		m, err := New(files, "/static/")
+		assert.NoError(t, err)
+		m.CDN = "https://cdn.example.com"
+
+		b := &strings.Builder{}
+		n := &mx.Node{Writer: b, Interceptors: []mx.Interceptor{m.Interceptor()}}
+		n.Link(mx.S(`rel="stylesheet" href="/static/app.css"`))
+		n.Img(mx.S(`src="/static/img/logo.png" srcset="/static/img/logo.png 1x, /static/img/logo@2x.png 2x" alt="Logo"`))
+		n.A(mx.S(`href="/static/missing.pdf"`), mx.Text("PDF"))
+		n.A(mx.S(`href="/about"`), mx.Text("About"))
+
+		assert.Equal(t, `<link rel="stylesheet" href="https://cdn.example.com/static/app.7c98040a.css" />`+
+			`<img src="https://cdn.example.com/static/img/logo.8f8cbb7d.png" `+
+			`srcset="https://cdn.example.com/static/img/logo.8f8cbb7d.png 1x, https://cdn.example.com/static/img/logo@2x.6dfc0bb8.png 2x" alt="Logo" />`+
+			`<a href="/static/missing.pdf">PDF</a><a href="/about">About</a>`, b.String())
+	})
+}
+
+func TestHandler(t *testing.T) {
+	testCases := []serveTestCase{
+		{path: "/static/app.7c98040a.css", status: http.StatusOK, body: "body{}", cacheControl: "public, max-age=31536000, immutable"},
+		{path: "/static/js/app.min.e4a62ff8.js", status: http.StatusOK, body: "go()", cacheControl: "public, max-age=31536000, immutable"},
+		{path: "/static/app.css", status: http.StatusOK, body: "body{}", cacheControl: "no-cache"},
+		{path: "/static/app.00000000.css", status: http.StatusNotFound},
+		{path: "/other/app.css", status: http.StatusNotFound},
+	}
+
+	m, err := New(files, "/static/")
+	assert.NoError(t, err)
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("serves '%v'", tc.path)
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, tc.body, w.Body.String())
+				assert.Equal(t, tc.cacheControl, w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package assets
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+// Lock maps the URLs of external files, e.g. scripts on a public CDN, to their
+// integrity. It is kept in a lockfile, a JSON object, so the hashes are
+// reviewed when they change instead of fetched at startup.
+type Lock map[string]string
+
+// Integrity returns the Subresource Integrity value of data, e.g.
+// "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO"
+// for "alert('Hello, world.');".
+func Integrity(data []byte) string {
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// FetchLock downloads urls and returns their integrity. Use it to update a
+// lockfile, e.g. from a go:generate command. If client is nil,
+// http.DefaultClient is used.
+func FetchLock(ctx context.Context, client *http.Client, urls ...string) (Lock, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	lock := Lock{}
+	for _, u := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("assets: fetching %v: %v", u, res.Status)
+		}
+		lock[u] = Integrity(data)
+	}
+	return lock, nil
+}
+
+// ReadLock reads a lockfile.
+func ReadLock(r io.Reader) (Lock, error) {
+	lock := Lock{}
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Write writes l as a lockfile, with the URLs sorted.
+func (l Lock) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Integrity returns the integrity of a file of the manifest, by name or URL,
+// or of an external URL in Lock. It returns "" if it isn't known.
+func (m *Manifest) Integrity(url string) string {
+	if sum, ok := m.Lock[url]; ok {
+		return sum
+	}
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if m.CDN != "" {
+		url = strings.TrimPrefix(url, m.CDN)
+	}
+	name := strings.TrimPrefix(url, m.prefix)
+	if file, ok := m.files[name]; ok {
+		name = file
+	}
+	return m.sums[name]
+}
+
+// Script renders a script from src, with its integrity when it is known.
+func (m *Manifest) Script(src string, attr mx.Attr) func(*mx.Node) {
+	src = m.URL(src)
+	return func(n *mx.Node) {
+		n.Script(mx.Slice{mx.M{"src": src}, m.sri(src), attr})
+	}
+}
+
+// Stylesheet renders a stylesheet link to href, with its integrity when it is
+// known.
+func (m *Manifest) Stylesheet(href string, attr mx.Attr) func(*mx.Node) {
+	href = m.URL(href)
+	return func(n *mx.Node) {
+		n.Link(mx.Slice{mx.S(`rel="stylesheet"`), mx.M{"href": href}, m.sri(href), attr})
+	}
+}
+
+// sri returns the integrity and crossorigin attributes of url, or nil if its
+// integrity isn't known.
+func (m *Manifest) sri(url string) mx.Attr {
+	sum := m.Integrity(url)
+	if sum == "" {
+		return nil
+	}
+	return mx.Slice{mx.M{"integrity": sum}, mx.S(`crossorigin="anonymous"`)}
+}
+
+// addIntegrity adds integrity and crossorigin to scripts and stylesheets
+// whose integrity is known and which don't have it yet.
+func (m *Manifest) addIntegrity(tag string, attrs []mx.Attribute) []mx.Attribute {
+	var url string
+	for _, a := range attrs {
+		switch {
+		case a.Name == "integrity":
+			return attrs
+		case tag == "script" && a.Name == "src",
+			tag == "link" && a.Name == "href":
+			url = a.Value
+		}
+	}
+	if url == "" || tag == "link" && !subresourceLink(attrs) {
+		return attrs
+	}
+	sum := m.Integrity(url)
+	if sum == "" {
+		return attrs
+	}
+	attrs = append(attrs, mx.Attribute{Name: "integrity", Value: sum})
+	for _, a := range attrs {
+		if a.Name == "crossorigin" {
+			return attrs
+		}
+	}
+	return append(attrs, mx.Attribute{Name: "crossorigin", Value: "anonymous"})
+}
+
+// subresourceLink checks if a link loads a subresource that can be checked
+// with integrity.
+func subresourceLink(attrs []mx.Attribute) bool {
+	for _, a := range attrs {
+		if a.Name == "rel" {
+			for rel := range strings.FieldsSeq(strings.ToLower(a.Value)) {
+				if rel == "stylesheet" || rel == "preload" || rel == "modulepreload" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
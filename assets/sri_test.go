@@ -0,0 +1,146 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	sriTestCase struct {
+		component func(*mx.Node)
+		expected  string
+	}
+)
+
+const (
+	// Integrity of "alert('Hello, world.');", from the Subresource Integrity spec
+	helloSRI = "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO"
+
+	htmxURL = "https://unpkg.com/htmx.org@2.0.4/dist/htmx.min.js"
+	htmxSRI = "sha384-locked"
+)
+
+var sriFiles = map[string]string{
+	"app.js":  "alert('Hello, world.');",
+	"app.css": "body{}",
+}
+
+func sriManifest(t *testing.T) *Manifest {
+	fsys := fstest.MapFS{}
+	for name, data := range sriFiles {
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	m, err := New(fsys, "/static/")
+	assert.NoError(t, err)
+	m.Lock = Lock{htmxURL: htmxSRI}
+	return m
+}
+
+func TestIntegrity(t *testing.T) {
+	t.Run("hashes with SHA-384", func(t *testing.T) {
+		assert.Equal(t, helloSRI, Integrity([]byte("alert('Hello, world.');")))
+	})
+}
+
+func TestSRI(t *testing.T) {
+	jsURL := "/static/app.ab39cb72.js"
+	testCases := []sriTestCase{
+		{
+			component: func(n *mx.Node) { n.Script(mx.S(`src="/static/app.js" defer`)) },
+			expected:  `<script src="` + jsURL + `" defer integrity="` + helloSRI + `" crossorigin="anonymous"></script>`,
+		},
+		{
+			component: func(n *mx.Node) { n.Script(mx.S(`src="` + htmxURL + `" crossorigin="use-credentials"`)) },
+			expected:  `<script src="` + htmxURL + `" crossorigin="use-credentials" integrity="` + htmxSRI + `"></script>`,
+		},
+		{
+			component: func(n *mx.Node) { n.Link(mx.S(`rel="icon" href="/static/app.css"`)) },
+			expected:  `<link rel="icon" href="/static/app.7c98040a.css" />`,
+		},
+		{
+			component: func(n *mx.Node) { n.Link(mx.S(`rel="preload" as="script" href="/static/app.js"`)) },
+			expected:  `<link rel="preload" as="script" href="` + jsURL + `" integrity="` + helloSRI + `" crossorigin="anonymous" />`,
+		},
+		{
+			component: func(n *mx.Node) { n.Script(mx.S(`src="/static/app.js" integrity="sha384-pinned"`)) },
+			expected:  `<script src="` + jsURL + `" integrity="sha384-pinned"></script>`,
+		},
+		{
+			component: func(n *mx.Node) { n.Script(mx.S(`src="https://example.com/other.js"`)) },
+			expected:  `<script src="https://example.com/other.js"></script>`,
+		},
+	}
+
+	m := sriManifest(t)
+	m.SRI = true
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders '%v'", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			n := &mx.Node{Writer: b, Interceptors: []mx.Interceptor{m.Interceptor()}}
+			tc.component(n)
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+
+	t.Run("looks up CDN URLs", func(t *testing.T) {
+		m := sriManifest(t)
+		m.CDN = "https://cdn.example.com"
+		assert.Equal(t, helloSRI, m.Integrity(m.URL("app.js")))
+		assert.Equal(t, helloSRI, m.Integrity("app.js"))
+		assert.Equal(t, "", m.Integrity("https://example.com/app.js"))
+	})
+}
+
+func TestHelpers(t *testing.T) {
+	t.Run("render scripts and stylesheets with integrity", func(t *testing.T) {
+		m := sriManifest(t)
+		b := &strings.Builder{}
+		n := &mx.Node{Writer: b}
+		m.Script("app.js", mx.S("defer"))(n)
+		m.Script(htmxURL, nil)(n)
+		m.Stylesheet("/static/missing.css", nil)(n)
+
+		assert.Equal(t, `<script src="/static/app.ab39cb72.js" integrity="`+helloSRI+`" crossorigin="anonymous" defer></script>`+
+			`<script src="`+htmxURL+`" integrity="`+htmxSRI+`" crossorigin="anonymous"></script>`+
+			`<link rel="stylesheet" href="/static/missing.css" />`, b.String())
+	})
+}
+
+func TestLock(t *testing.T) {
+	t.Run("fetches, writes and reads lockfiles", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/hello.js" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte("alert('Hello, world.');"))
+		}))
+		defer srv.Close()
+
+		lock, err := FetchLock(context.Background(), srv.Client(), srv.URL+"/hello.js")
+		assert.NoError(t, err)
+		assert.Equal(t, Lock{srv.URL + "/hello.js": helloSRI}, lock)
+
+		b := &strings.Builder{}
+		assert.NoError(t, lock.Write(b))
+		assert.Equal(t, "{\n  \""+srv.URL+"/hello.js\": \""+helloSRI+"\"\n}\n", b.String())
+
+		read, err := ReadLock(strings.NewReader(b.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, lock, read)
+
+		_, err = FetchLock(context.Background(), srv.Client(), srv.URL+"/missing.js")
+		assert.EqualError(t, err, "assets: fetching "+srv.URL+"/missing.js: 404 Not Found")
+	})
+}
@@ -11,10 +11,12 @@ type (
 		Attributes() string
 	}
 
-	// S is a raw string attribute.
+	// S is a raw string attribute. It is written verbatim, so it must not
+	// contain untrusted values.
 	S string
 
-	// M represents key-value HTML attributes.
+	// M represents key-value HTML attributes. URL-valued attributes (href, src,
+	// action and formaction) with a disallowed scheme are replaced by "#ZgotmplZ".
 	M map[string]string
 
 	// N represents conditional attributes like classes.
@@ -43,6 +45,9 @@ func (m M) Attributes() string {
 			b.WriteByte(' ')
 		}
 		b.WriteString(k)
+		if isURLAttr(k) {
+			v = sanitizeURL(v)
+		}
 		if !isVoidAttr(k) || v != "" {
 			b.WriteString(`="` + html.EscapeString(v) + `"`)
 		}
@@ -77,6 +82,9 @@ func (n N) Attributes() string {
 		var vals []string
 		for val, ok := range conds {
 			if ok {
+				if isURLAttr(k) {
+					val = sanitizeURL(val)
+				}
 				vals = append(vals, html.EscapeString(val))
 			}
 		}
@@ -111,3 +119,60 @@ func RegisterVoidAttrs(attrs ...string) {
 func isVoidAttr(attr string) bool {
 	return attr == "disabled" || attr == "defer" || attr == "open" || customVoidAttrs[attr]
 }
+
+// attribute is an attribute parsed from a rendered attribute string. The value
+// is kept escaped, as it was written.
+type attribute struct {
+	name     string
+	value    string
+	quote    byte // the quote around value, or 0 if unquoted
+	hasValue bool
+}
+
+// parseAttrs splits a rendered attribute string, e.g. `a="x" b c='y' d=z`.
+func parseAttrs(s string) []attribute {
+	var attrs []attribute
+	i := 0
+	for i < len(s) {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' {
+			i++
+		}
+		if i == start {
+			if i < len(s) {
+				i++ // stray '='
+			}
+			continue
+		}
+		a := attribute{name: s[start:i]}
+		if i < len(s) && s[i] == '=' {
+			i++
+			a.hasValue = true
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				a.quote = s[i]
+				end := strings.IndexByte(s[i+1:], a.quote)
+				if end < 0 {
+					end = len(s) - i - 1
+				}
+				a.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isSpace(s[i]) {
+					i++
+				}
+				a.value = s[start:i]
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs
+}
+
+// isSpace checks if c is HTML whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -123,3 +123,14 @@ func TestAttr(t *testing.T) {
 		}
 	})
 }
+
+func TestParseAttrs(t *testing.T) {
+	attrs := parseAttrs(`a="x y" b c='say "hi"' d=z  e=""`)
+	assert.Equal(t, []attribute{
+		{name: "a", value: "x y", quote: '"', hasValue: true},
+		{name: "b"},
+		{name: "c", value: `say "hi"`, quote: '\'', hasValue: true},
+		{name: "d", value: "z", hasValue: true},
+		{name: "e", quote: '"', hasValue: true},
+	}, attrs)
+}
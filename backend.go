@@ -0,0 +1,131 @@
+package mx
+
+import (
+	"errors"
+	"html"
+	"io"
+)
+
+type (
+	// Backend receives the output of a Node as element-level events, e.g. to
+	// build a tree, count elements or write to several destinations.
+	Backend interface {
+		OpenTag(tag string, attrs []Attribute) error // void elements are closed right away
+		Text(text string) error                      // unescaped text
+		Raw(html HTML) error                         // markup written as it is
+		CloseTag(tag string) error
+	}
+
+	// WriterBackend writes the events as HTML to W.
+	WriterBackend struct {
+		W io.Writer
+	}
+
+	// tee sends the events to several backends.
+	tee []Backend
+)
+
+// Tee returns a backend that sends the events to each of backends.
+func Tee(backends ...Backend) Backend {
+	return tee(backends)
+}
+
+func (w *WriterBackend) OpenTag(tag string, attrs []Attribute) error {
+	s := "<" + tag
+	if len(attrs) > 0 {
+		s += " " + string(attrString(sanitizeAttrs(attrs)))
+	}
+	if isVoidTag(tag) {
+		s += " />"
+	} else {
+		s += ">"
+	}
+	_, err := io.WriteString(w.W, s)
+	return err
+}
+
+func (w *WriterBackend) Text(text string) error {
+	_, err := io.WriteString(w.W, html.EscapeString(text))
+	return err
+}
+
+func (w *WriterBackend) Raw(raw HTML) error {
+	_, err := io.WriteString(w.W, string(raw))
+	return err
+}
+
+func (w *WriterBackend) CloseTag(tag string) error {
+	if isVoidTag(tag) {
+		return nil
+	}
+	_, err := io.WriteString(w.W, "</"+tag+">")
+	return err
+}
+
+func (t tee) OpenTag(tag string, attrs []Attribute) error {
+	var errs []error
+	for _, b := range t {
+		errs = append(errs, b.OpenTag(tag, attrs))
+	}
+	return errors.Join(errs...)
+}
+
+func (t tee) Text(text string) error {
+	var errs []error
+	for _, b := range t {
+		errs = append(errs, b.Text(text))
+	}
+	return errors.Join(errs...)
+}
+
+func (t tee) Raw(raw HTML) error {
+	var errs []error
+	for _, b := range t {
+		errs = append(errs, b.Raw(raw))
+	}
+	return errors.Join(errs...)
+}
+
+func (t tee) CloseTag(tag string) error {
+	var errs []error
+	for _, b := range t {
+		errs = append(errs, b.CloseTag(tag))
+	}
+	return errors.Join(errs...)
+}
+
+// emitting checks if n sends events to a backend and the output isn't
+// discarded.
+func (n *Node) emitting() bool {
+	return n.Backend != nil && n.err == nil && !n.suppressed()
+}
+
+// emitOpen sends the start tag of an element, whose attributes were rendered
+// from attr.
+func (n *Node) emitOpen(tag string, attr Attr, attrs string) {
+	n.err = n.Backend.OpenTag(tag, trustURLs(parseAttributes(attrs), attr))
+}
+
+// trustURLs marks the attributes that attr sets with U as trusted.
+func trustURLs(attrs []Attribute, attr Attr) []Attribute {
+	switch attr := attr.(type) {
+	case U:
+		for i, a := range attrs {
+			if _, ok := attr[a.Name]; ok {
+				attrs[i].Trusted = true
+			}
+		}
+	case Slice:
+		for _, a := range attr {
+			attrs = trustURLs(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// emitClose sends the end tag of an element opened by emitOpen.
+func (n *Node) emitClose(tag string) {
+	if n.err == nil {
+		n.err = n.Backend.CloseTag(tag)
+	}
+}
@@ -0,0 +1,158 @@
+package mx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// recorder records the events it receives.
+	recorder struct {
+		events []string
+		fail   string // event that fails
+	}
+
+	backendTestCase struct {
+		component func(*Node)
+		expected  []string
+	}
+)
+
+var errBackend = errors.New("backend failed")
+
+func (r *recorder) record(event string) error {
+	r.events = append(r.events, event)
+	if event == r.fail {
+		return errBackend
+	}
+	return nil
+}
+
+func (r *recorder) OpenTag(tag string, attrs []Attribute) error {
+	return r.record(fmt.Sprintf("open %v %v", tag, attrs))
+}
+
+func (r *recorder) Text(text string) error { return r.record("text " + text) }
+
+func (r *recorder) Raw(raw HTML) error { return r.record("raw " + string(raw)) }
+
+func (r *recorder) CloseTag(tag string) error { return r.record("close " + tag) }
+
+func TestBackend(t *testing.T) {
+	testCases := []backendTestCase{
+		{
+			component: func(n *Node) {
+				n.Ul(S(`class="list"`), func(n *Node) {
+					n.Li(nil, Text("a & b"))
+					n.Li(nil, Raw("<b>c</b>"))
+				})
+			},
+			expected: []string{
+				"open ul [{class list false}]",
+				"open li []", "text a & b", "close li",
+				"open li []", "raw <b>c</b>", "close li",
+				"close ul",
+			},
+		},
+		{
+			component: func(n *Node) {
+				n.P(nil, Text("line"))
+				n.Br(nil)
+				n.Input(S(`type="checkbox" checked`))
+			},
+			expected: []string{
+				"open p []", "text line", "close p",
+				"open br []", "close br",
+				"open input [{type checkbox false} {checked  false}]", "close input",
+			},
+		},
+		{
+			component: func(n *Node) {
+				n.DevMode = true
+				n.Minify = true
+				n.Div(nil, func(n *Node) {
+					n.P(nil, Text("  spaced  "))
+				})
+			},
+			expected: []string{
+				"open div []",
+				"open p []", "text   spaced  ", "close p",
+				"close div",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		name := fmt.Sprintf("sends the events of component %d", i)
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			n := &Node{Backend: r}
+			tc.component(n)
+			assert.NoError(t, Error(n))
+			assert.Equal(t, tc.expected, r.events)
+		})
+	}
+
+	t.Run("marks URLs set with U as trusted", func(t *testing.T) {
+		tree, err := Build(func(n *Node) {
+			n.A(Slice{U{"href": "javascript:back()"}, S(`src="javascript:x"`)}, Text("Back"))
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []Attribute{{Name: "href", Value: "javascript:back()", Trusted: true}, {Name: "src", Value: "javascript:x"}},
+			tree.Find("a")[0].Attrs)
+
+		b := &strings.Builder{}
+		tree.Render(&Node{Writer: b})
+		assert.Equal(t, `<a href="javascript:back()" src="#ZgotmplZ">Back</a>`, b.String())
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		r := &recorder{fail: "text b"}
+		n := &Node{Backend: r}
+		n.Div(nil, Text("a"), Text("b"), Text("c"))
+		assert.Equal(t, errBackend, Error(n))
+		assert.Equal(t, []string{"open div []", "text a", "text b"}, r.events)
+	})
+}
+
+func TestWriterBackend(t *testing.T) {
+	t.Run("writes what Writer gets", func(t *testing.T) {
+		expected := &strings.Builder{}
+		treeGallery(&Node{Writer: expected})
+		b := &strings.Builder{}
+		treeGallery(&Node{Backend: &WriterBackend{W: b}})
+		assert.Equal(t, expected.String(), b.String())
+	})
+
+	t.Run("checks URLs unless trusted", func(t *testing.T) {
+		b := &strings.Builder{}
+		w := &WriterBackend{W: b}
+		assert.NoError(t, w.OpenTag("a", []Attribute{{Name: "href", Value: "javascript:alert(1)"}}))
+		assert.NoError(t, w.OpenTag("a", []Attribute{{Name: "href", Value: "javascript:back()", Trusted: true}}))
+		assert.Equal(t, `<a href="#ZgotmplZ"><a href="javascript:back()">`, b.String())
+	})
+}
+
+func TestTee(t *testing.T) {
+	t.Run("sends the events to each backend", func(t *testing.T) {
+		tree := NewTreeBuilder()
+		b := &strings.Builder{}
+		n := &Node{Backend: Tee(tree, &WriterBackend{W: b})}
+		n.P(S(`id="x"`), Text("hi"))
+
+		assert.NoError(t, Error(n))
+		assert.Equal(t, `<p id="x">hi</p>`, b.String())
+		assert.Equal(t, "hi", tree.Root().Find("p")[0].TextContent())
+	})
+
+	t.Run("joins the errors", func(t *testing.T) {
+		r := &recorder{fail: "open p []"}
+		n := &Node{Backend: Tee(r, NewTreeBuilder())}
+		n.P(nil, Text("hi"))
+		assert.ErrorIs(t, Error(n), errBackend)
+	})
+}
@@ -0,0 +1,204 @@
+package mx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"strings"
+	"sync"
+)
+
+type (
+	// Fragment is rendered HTML to swap into an element of a page that is
+	// already loaded, e.g. pushed over a WebSocket.
+	Fragment struct {
+		Target string `json:"target"` // id of the element
+		Swap   string `json:"swap"`   // how the HTML is swapped in, e.g. SwapInner
+		HTML   HTML   `json:"html"`
+	}
+
+	// FragmentEncoder encodes a fragment into a message.
+	FragmentEncoder func(Fragment) ([]byte, error)
+
+	// Transport sends messages to a client, e.g. over a WebSocket connection.
+	Transport interface {
+		Send(msg []byte) error
+	}
+
+	// TransportFunc adapts a function to Transport.
+	TransportFunc func(msg []byte) error
+
+	// Pusher renders fragments and sends them through a transport.
+	Pusher struct {
+		Transport Transport
+		Encode    FragmentEncoder // EncodeJSON if nil
+	}
+
+	// MemoryTransport keeps the messages sent, for tests.
+	MemoryTransport struct {
+		mu       sync.Mutex
+		messages [][]byte
+	}
+)
+
+// Swap strategies, named as in HTMX
+const (
+	SwapOuter       = "outerHTML"
+	SwapInner       = "innerHTML"
+	SwapBeforeBegin = "beforebegin"
+	SwapAfterBegin  = "afterbegin"
+	SwapBeforeEnd   = "beforeend"
+	SwapAfterEnd    = "afterend"
+	SwapDelete      = "delete"
+)
+
+// ErrNoRoot is returned when an outerHTML fragment encoded for HTMX has no
+// root element to carry the hx-swap-oob attribute.
+var ErrNoRoot = errors.New("mx: outerHTML fragment without a root element")
+
+func (f TransportFunc) Send(msg []byte) error {
+	return f(msg)
+}
+
+// RenderFragment renders component into a fragment for target.
+func RenderFragment(target, swap string, component func(*Node)) (Fragment, error) {
+	b := &strings.Builder{}
+	n := &Node{Writer: b}
+	component(n)
+	if err := Error(n); err != nil {
+		return Fragment{}, err
+	}
+	return Fragment{Target: target, Swap: swap, HTML: HTML(b.String())}, nil
+}
+
+// EncodeJSON encodes a fragment as {"target": ..., "swap": ..., "html": ...},
+// for clients with their own swapping code.
+func EncodeJSON(f Fragment) ([]byte, error) {
+	b := &bytes.Buffer{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(f); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// EncodeHTMX encodes a fragment as an out-of-band swap, the format of the HTMX
+// WebSocket extension of htmx 2.x, which parses each message inside a
+// <template> and swaps each of its top-level elements. For outerHTML swaps,
+// the root element of the HTML replaces the target. For the others, the HTML
+// is wrapped in an element whose children are swapped into the target: a
+// <div>, or the parent that table rows and cells, and select options, need to
+// be parsed in, e.g. a <tbody> for rows.
+func EncodeHTMX(f Fragment) ([]byte, error) {
+	swap := f.Swap
+	if swap == "" {
+		swap = SwapOuter
+	}
+	s := string(f.HTML)
+	start := rootTag(s)
+	end := start + 1
+	for start >= 0 && end < len(s) && !isSpace(s[end]) && s[end] != '>' && s[end] != '/' {
+		end++
+	}
+
+	if swap != SwapOuter {
+		wrapper := "div"
+		if parent, ok := parentTags[strings.ToLower(s[start+1:end])]; ok && start >= 0 {
+			wrapper = parent
+		}
+		return []byte(`<` + wrapper + ` id="` + html.EscapeString(f.Target) + `" hx-swap-oob="` + html.EscapeString(swap) + `">` +
+			s + `</` + wrapper + `>`), nil
+	}
+
+	if start < 0 {
+		return nil, ErrNoRoot
+	}
+	oob := ` hx-swap-oob="outerHTML:` + html.EscapeString(idSelector(f.Target)) + `"`
+	return []byte(s[:end] + oob + s[end:]), nil
+}
+
+// Push renders component and sends it to target.
+func (p *Pusher) Push(target, swap string, component func(*Node)) error {
+	f, err := RenderFragment(target, swap, component)
+	if err != nil {
+		return err
+	}
+	encode := p.Encode
+	if encode == nil {
+		encode = EncodeJSON
+	}
+	msg, err := encode(f)
+	if err != nil {
+		return err
+	}
+	return p.Transport.Send(msg)
+}
+
+func (t *MemoryTransport) Send(msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (t *MemoryTransport) Messages() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]byte{}, t.messages...)
+}
+
+// Reset discards the messages sent so far.
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
+
+// rootTag returns the index of the start tag of the first element in s,
+// skipping whitespace and comments, or -1 if s doesn't start with an element.
+func rootTag(s string) int {
+	i := 0
+	for i < len(s) {
+		switch {
+		case isSpace(s[i]):
+			i++
+		case strings.HasPrefix(s[i:], "<!--"):
+			end := strings.Index(s[i:], "-->")
+			if end < 0 {
+				return -1
+			}
+			i += end + 3
+		case s[i] == '<' && i+1 < len(s) && isLetter(s[i+1]):
+			return i
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// Elements that are only parsed inside tables or selects, and the parent
+// each needs
+var parentTags = map[string]string{
+	"caption": "table", "colgroup": "table", "thead": "table", "tbody": "table", "tfoot": "table",
+	"col": "colgroup", "tr": "tbody", "td": "tr", "th": "tr", "option": "select", "optgroup": "select",
+}
+
+// idSelector returns a CSS selector for the element with id.
+func idSelector(id string) string {
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !isLetter(c) && c != '-' && c != '_' && (c < '0' || c > '9' || i == 0) {
+			return `[id="` + strings.ReplaceAll(strings.ReplaceAll(id, `\`, `\\`), `"`, `\"`) + `"]`
+		}
+	}
+	return "#" + id
+}
+
+// isLetter checks if c is an ASCII letter.
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,140 @@
+package mx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type (
+	encodeTestCase struct {
+		fragment Fragment
+		expected string
+		err      error
+	}
+)
+
+func TestEncodeHTMX(t *testing.T) {
+	testCases := []encodeTestCase{
+		{
+			fragment: Fragment{Target: "count", Swap: SwapInner, HTML: "3 items"},
+			expected: `<div id="count" hx-swap-oob="innerHTML">3 items</div>`,
+		},
+		{
+			fragment: Fragment{Target: "list", Swap: SwapBeforeEnd, HTML: "<li>new</li>"},
+			expected: `<div id="list" hx-swap-oob="beforeend"><li>new</li></div>`,
+		},
+		{
+			fragment: Fragment{Target: "todo-1", Swap: SwapOuter, HTML: `<li class="done">one</li>`},
+			expected: `<li hx-swap-oob="outerHTML:#todo-1" class="done">one</li>`,
+		},
+		{
+			fragment: Fragment{Target: "todo-1", HTML: " <!-- c --><br />"},
+			expected: ` <!-- c --><br hx-swap-oob="outerHTML:#todo-1" />`,
+		},
+		{
+			fragment: Fragment{Target: `1 "a"`, HTML: "<p>x</p>"},
+			expected: `<p hx-swap-oob="outerHTML:[id=&#34;1 \&#34;a\&#34;&#34;]">x</p>`,
+		},
+		{
+			fragment: Fragment{Target: "rows", Swap: SwapBeforeEnd, HTML: `<tr><td>1</td></tr>`},
+			expected: `<tbody id="rows" hx-swap-oob="beforeend"><tr><td>1</td></tr></tbody>`,
+		},
+		{
+			fragment: Fragment{Target: "row-1", HTML: `<TR class="a"><td>1</td></TR>`},
+			expected: `<TR hx-swap-oob="outerHTML:#row-1" class="a"><td>1</td></TR>`,
+		},
+		{
+			fragment: Fragment{Target: "colors", Swap: SwapInner, HTML: `<option>red</option><option>blue</option>`},
+			expected: `<select id="colors" hx-swap-oob="innerHTML"><option>red</option><option>blue</option></select>`,
+		},
+		{
+			fragment: Fragment{Target: "row-1", Swap: SwapAfterBegin, HTML: `<td>1</td>`},
+			expected: `<tr id="row-1" hx-swap-oob="afterbegin"><td>1</td></tr>`,
+		},
+		{
+			fragment: Fragment{Target: "x", Swap: SwapOuter, HTML: "text"},
+			err:      ErrNoRoot,
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("encodes %+v", tc.fragment)
+		t.Run(name, func(t *testing.T) {
+			msg, err := EncodeHTMX(tc.fragment)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, string(msg))
+		})
+	}
+
+	// htmx 2.x parses the message inside a <template>, and the ws extension
+	// swaps each top-level element with its hx-swap-oob.
+	for _, tc := range testCases[5:9] {
+		name := fmt.Sprintf("keeps %v in elements parsed by HTMX", tc.fragment.HTML)
+		t.Run(name, func(t *testing.T) {
+			ctx := &html.Node{Type: html.ElementNode, Data: "template", DataAtom: atom.Template}
+			nodes, err := html.ParseFragment(strings.NewReader(tc.expected), ctx)
+			assert.NoError(t, err)
+			assert.Len(t, nodes, 1)
+			assert.Contains(t, nodes[0].Attr, html.Attribute{Key: "hx-swap-oob", Val: oobSwap(tc.fragment)})
+
+			b := &strings.Builder{}
+			assert.NoError(t, html.Render(b, nodes[0]))
+			assert.Equal(t, strings.ToLower(tc.expected), strings.ToLower(b.String()))
+		})
+	}
+}
+
+func TestPusher(t *testing.T) {
+	t.Run("sends JSON envelopes by default", func(t *testing.T) {
+		tr := &MemoryTransport{}
+		p := &Pusher{Transport: tr}
+		assert.NoError(t, p.Push("count", SwapInner, Text("3 <items>")))
+		assert.NoError(t, p.Push("list", SwapBeforeEnd, func(n *Node) { n.Li(nil, Text("new")) }))
+		assert.Equal(t, []string{
+			`{"target":"count","swap":"innerHTML","html":"3 &lt;items&gt;"}`,
+			`{"target":"list","swap":"beforeend","html":"<li>new</li>"}`,
+		}, messages(tr))
+
+		tr.Reset()
+		assert.Empty(t, tr.Messages())
+	})
+
+	t.Run("sends HTMX messages", func(t *testing.T) {
+		tr := &MemoryTransport{}
+		p := &Pusher{Transport: tr, Encode: EncodeHTMX}
+		assert.NoError(t, p.Push("todo-1", SwapOuter, func(n *Node) { n.Li(nil, Text("one")) }))
+		assert.Equal(t, []string{`<li hx-swap-oob="outerHTML:#todo-1">one</li>`}, messages(tr))
+	})
+
+	t.Run("returns transport and encoding errors", func(t *testing.T) {
+		failure := errors.New("closed")
+		p := &Pusher{Transport: TransportFunc(func([]byte) error { return failure })}
+		assert.ErrorIs(t, p.Push("x", SwapInner, Text("x")), failure)
+
+		p = &Pusher{Transport: &MemoryTransport{}, Encode: EncodeHTMX}
+		assert.ErrorIs(t, p.Push("x", SwapOuter, Text("x")), ErrNoRoot)
+	})
+}
+
+// oobSwap returns the hx-swap-oob value of a fragment encoded for HTMX.
+func oobSwap(f Fragment) string {
+	if f.Swap == "" || f.Swap == SwapOuter {
+		return "outerHTML:" + idSelector(f.Target)
+	}
+	return f.Swap
+}
+
+// messages returns the messages of a transport as strings.
+func messages(t *MemoryTransport) []string {
+	s := []string{}
+	for _, m := range t.Messages() {
+		s = append(s, string(m))
+	}
+	return s
+}
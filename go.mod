@@ -3,12 +3,17 @@ module github.com/jlucasnsilva/mx
 go 1.24.2
 
 require (
+	github.com/andybalholm/cascadia v1.3.3
 	github.com/stretchr/testify v1.10.0
+	github.com/yuin/goldmark v1.8.2
 	golang.org/x/net v0.42.0
+	golang.org/x/tools v0.35.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.26.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
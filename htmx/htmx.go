@@ -0,0 +1,284 @@
+// Package htmx provides typed helpers for HTMX: attributes for elements,
+// request detection and response headers for handlers, and out-of-band swaps.
+//
+//	n.Input(mx.Slice{
+//		mx.M{"type": "search", "name": "q"},
+//		htmx.Get("/search"),
+//		htmx.Trigger(htmx.Event("input").Changed().Delay(300 * time.Millisecond)),
+//		htmx.Target("#results"),
+//		htmx.Swap(htmx.SwapInnerHTML.Transition()),
+//	})
+package htmx
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlucasnsilva/mx"
+	"github.com/jlucasnsilva/mx/internal/duration"
+)
+
+type (
+	// SwapStyle is an hx-swap value: a style with optional modifiers, e.g.
+	// htmx.SwapOuterHTML.Settle(100 * time.Millisecond).
+	SwapStyle string
+
+	// TriggerSpec is an hx-trigger event with optional modifiers, e.g.
+	// htmx.Event("keyup").Changed().Delay(500 * time.Millisecond).
+	TriggerSpec string
+)
+
+// Swap styles
+const (
+	SwapInnerHTML   SwapStyle = "innerHTML"
+	SwapOuterHTML   SwapStyle = "outerHTML"
+	SwapTextContent SwapStyle = "textContent"
+	SwapBeforeBegin SwapStyle = "beforebegin"
+	SwapAfterBegin  SwapStyle = "afterbegin"
+	SwapBeforeEnd   SwapStyle = "beforeend"
+	SwapAfterEnd    SwapStyle = "afterend"
+	SwapDelete      SwapStyle = "delete"
+	SwapNone        SwapStyle = "none"
+)
+
+// Transition uses the View Transitions API for the swap.
+func (s SwapStyle) Transition() SwapStyle {
+	return s.with("transition:true")
+}
+
+// SwapDelay waits d between receiving the response and swapping.
+func (s SwapStyle) SwapDelay(d time.Duration) SwapStyle {
+	return s.with("swap:" + duration.Format(d))
+}
+
+// Settle waits d between swapping and settling.
+func (s SwapStyle) Settle(d time.Duration) SwapStyle {
+	return s.with("settle:" + duration.Format(d))
+}
+
+// IgnoreTitle keeps the page title when the response has a <title>.
+func (s SwapStyle) IgnoreTitle() SwapStyle {
+	return s.with("ignoreTitle:true")
+}
+
+// Scroll scrolls target ("top", "bottom", or "selector:top") after the swap.
+func (s SwapStyle) Scroll(target string) SwapStyle {
+	return s.with("scroll:" + target)
+}
+
+// Show scrolls target ("top", "bottom", or "selector:top") into view after the
+// swap.
+func (s SwapStyle) Show(target string) SwapStyle {
+	return s.with("show:" + target)
+}
+
+// FocusScroll sets whether focused inputs are scrolled into view.
+func (s SwapStyle) FocusScroll(scroll bool) SwapStyle {
+	return s.with("focus-scroll:" + strconv.FormatBool(scroll))
+}
+
+func (s SwapStyle) with(modifier string) SwapStyle {
+	return s + SwapStyle(" "+modifier)
+}
+
+// Event creates a trigger for an event, e.g. "click" or "keyup[key=='Enter']".
+func Event(name string) TriggerSpec {
+	return TriggerSpec(name)
+}
+
+// Every creates a polling trigger.
+func Every(d time.Duration) TriggerSpec {
+	return TriggerSpec("every " + duration.Format(d))
+}
+
+// Once triggers at most once.
+func (t TriggerSpec) Once() TriggerSpec {
+	return t.with("once")
+}
+
+// Changed triggers only if the value of the element changed.
+func (t TriggerSpec) Changed() TriggerSpec {
+	return t.with("changed")
+}
+
+// Delay waits d without new events before triggering.
+func (t TriggerSpec) Delay(d time.Duration) TriggerSpec {
+	return t.with("delay:" + duration.Format(d))
+}
+
+// Throttle triggers at most once every d.
+func (t TriggerSpec) Throttle(d time.Duration) TriggerSpec {
+	return t.with("throttle:" + duration.Format(d))
+}
+
+// From listens for the event on another element, e.g. "body" or "closest form".
+func (t TriggerSpec) From(selector string) TriggerSpec {
+	return t.with("from:" + selector)
+}
+
+// Target triggers only for events whose target matches selector.
+func (t TriggerSpec) Target(selector string) TriggerSpec {
+	return t.with("target:" + selector)
+}
+
+// Consume stops the event from triggering requests on parent elements.
+func (t TriggerSpec) Consume() TriggerSpec {
+	return t.with("consume")
+}
+
+// Queue sets which events are queued while a request is in flight: "first",
+// "last", "all" or "none".
+func (t TriggerSpec) Queue(which string) TriggerSpec {
+	return t.with("queue:" + which)
+}
+
+func (t TriggerSpec) with(modifier string) TriggerSpec {
+	return t + TriggerSpec(" "+modifier)
+}
+
+// Get issues a GET request to url.
+func Get(url string) mx.Attr { return mx.M{"hx-get": url} }
+
+// Post issues a POST request to url.
+func Post(url string) mx.Attr { return mx.M{"hx-post": url} }
+
+// Put issues a PUT request to url.
+func Put(url string) mx.Attr { return mx.M{"hx-put": url} }
+
+// Patch issues a PATCH request to url.
+func Patch(url string) mx.Attr { return mx.M{"hx-patch": url} }
+
+// Delete issues a DELETE request to url.
+func Delete(url string) mx.Attr { return mx.M{"hx-delete": url} }
+
+// Trigger sets the events that issue the request.
+func Trigger(specs ...TriggerSpec) mx.Attr {
+	s := make([]string, len(specs))
+	for i, spec := range specs {
+		s[i] = string(spec)
+	}
+	return mx.M{"hx-trigger": strings.Join(s, ", ")}
+}
+
+// Target sets the element swapped with the response, e.g. "#list" or
+// "closest tr".
+func Target(selector string) mx.Attr { return mx.M{"hx-target": selector} }
+
+// Swap sets how the response is swapped in.
+func Swap(style SwapStyle) mx.Attr { return mx.M{"hx-swap": string(style)} }
+
+// SwapOOB marks an element in a response to be swapped out of band: in place
+// of the element with the same id, or into selector when set.
+func SwapOOB(style SwapStyle, selector string) mx.Attr {
+	v := string(style)
+	if v == "" {
+		v = "true"
+	}
+	if selector != "" {
+		v += ":" + selector
+	}
+	return mx.M{"hx-swap-oob": v}
+}
+
+// Select picks the part of the response to swap in.
+func Select(selector string) mx.Attr { return mx.M{"hx-select": selector} }
+
+// SelectOOB picks elements of the response to swap out of band, e.g.
+// "#alerts:afterbegin, #count".
+func SelectOOB(selectors string) mx.Attr { return mx.M{"hx-select-oob": selectors} }
+
+// PushURL pushes url, or the request URL if url is "true", to the history.
+func PushURL(url string) mx.Attr { return mx.M{"hx-push-url": url} }
+
+// ReplaceURL replaces the current URL in the history with url, or the request
+// URL if url is "true".
+func ReplaceURL(url string) mx.Attr { return mx.M{"hx-replace-url": url} }
+
+// Boost turns links and forms in the element into HTMX requests.
+func Boost(boost bool) mx.Attr { return mx.M{"hx-boost": strconv.FormatBool(boost)} }
+
+// Confirm asks for confirmation before the request.
+func Confirm(message string) mx.Attr { return mx.M{"hx-confirm": message} }
+
+// Prompt asks for a value sent in the HX-Prompt header.
+func Prompt(message string) mx.Attr { return mx.M{"hx-prompt": message} }
+
+// Indicator sets the element that gets the htmx-request class during requests.
+func Indicator(selector string) mx.Attr { return mx.M{"hx-indicator": selector} }
+
+// DisabledElt sets the elements disabled during requests.
+func DisabledElt(selector string) mx.Attr { return mx.M{"hx-disabled-elt": selector} }
+
+// Include adds the values of other elements to the request.
+func Include(selector string) mx.Attr { return mx.M{"hx-include": selector} }
+
+// Params filters the parameters sent: "*", "none", "not a,b" or "a,b".
+func Params(params string) mx.Attr { return mx.M{"hx-params": params} }
+
+// Vals adds values to the request, encoded as JSON. It panics if vals has
+// values JSON can't encode, like functions, channels or NaN; use ValsJSON for
+// values that may not encode.
+func Vals(vals map[string]any) mx.Attr { return mx.M{"hx-vals": mustJSON(vals)} }
+
+// ValsJSON is like Vals but returns an error if vals can't be encoded.
+func ValsJSON(vals map[string]any) (mx.Attr, error) {
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	return mx.M{"hx-vals": string(b)}, nil
+}
+
+// Headers adds headers to the request, encoded as JSON.
+func Headers(headers map[string]string) mx.Attr { return mx.M{"hx-headers": mustJSON(headers)} }
+
+// Sync synchronizes requests with another element, e.g. "closest form:abort".
+func Sync(spec string) mx.Attr { return mx.M{"hx-sync": spec} }
+
+// Encoding sets the request encoding, e.g. "multipart/form-data".
+func Encoding(encoding string) mx.Attr { return mx.M{"hx-encoding": encoding} }
+
+// Ext enables extensions, e.g. "json-enc".
+func Ext(extensions ...string) mx.Attr { return mx.M{"hx-ext": strings.Join(extensions, ",")} }
+
+// On handles an event with an inline script, e.g. On("htmx:after-request", "this.reset()").
+func On(event, script string) mx.Attr { return mx.M{"hx-on:" + event: script} }
+
+// Preserve keeps the element, which must have an id, unchanged across swaps.
+func Preserve() mx.Attr { return mx.S(`hx-preserve="true"`) }
+
+// Disable disables HTMX processing in the element.
+func Disable() mx.Attr { return mx.S(`hx-disable="true"`) }
+
+// Validate validates forms before requests.
+func Validate() mx.Attr { return mx.S(`hx-validate="true"`) }
+
+// Disinherit stops the children from inheriting attributes, e.g. "*" or
+// "hx-target hx-select".
+func Disinherit(attrs string) mx.Attr { return mx.M{"hx-disinherit": attrs} }
+
+// History sets whether the page is saved in the history cache.
+func History(save bool) mx.Attr { return mx.M{"hx-history": strconv.FormatBool(save)} }
+
+// HistoryElt sets the element snapshotted for the history cache.
+func HistoryElt() mx.Attr { return mx.S(`hx-history-elt`) }
+
+// OOB renders children in a <div> swapped out of band into selector with
+// style, to send along with the main response. Content that can't be in a
+// <div>, like table rows, goes in a <template> with SwapOOB on each element.
+func OOB(style SwapStyle, selector string, children ...func(*mx.Node)) func(*mx.Node) {
+	return func(n *mx.Node) {
+		n.Div(SwapOOB(style, selector), children...)
+	}
+}
+
+// mustJSON encodes v, and panics if v has values JSON can't encode.
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic("htmx: " + err.Error())
+	}
+	return string(b)
+}
@@ -0,0 +1,83 @@
+package htmx
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	attrTestCase struct {
+		attr     mx.Attr
+		expected string
+	}
+)
+
+func TestAttrs(t *testing.T) {
+	testCases := []attrTestCase{
+		{attr: Get("/search?q=a&b"), expected: `hx-get="/search?q=a&amp;b"`},
+		{attr: Post("/todos"), expected: `hx-post="/todos"`},
+		{attr: Delete("/todos/1"), expected: `hx-delete="/todos/1"`},
+		{attr: Target("closest tr"), expected: `hx-target="closest tr"`},
+		{attr: Swap(SwapOuterHTML), expected: `hx-swap="outerHTML"`},
+		{
+			attr:     Swap(SwapBeforeEnd.Transition().SwapDelay(time.Second).Settle(100 * time.Millisecond).Scroll("bottom")),
+			expected: `hx-swap="beforeend transition:true swap:1s settle:100ms scroll:bottom"`,
+		},
+		{attr: Swap(SwapInnerHTML.Show("#top:top").IgnoreTitle().FocusScroll(false)), expected: `hx-swap="innerHTML show:#top:top ignoreTitle:true focus-scroll:false"`},
+		{
+			attr:     Trigger(Event("input").Changed().Delay(300*time.Millisecond), Event("search")),
+			expected: `hx-trigger="input changed delay:300ms, search"`,
+		},
+		{attr: Trigger(Every(2 * time.Second)), expected: `hx-trigger="every 2s"`},
+		{
+			attr:     Trigger(Event("keyup[key=='Enter']").From("body").Throttle(time.Second).Once().Consume().Queue("last").Target("input")),
+			expected: `hx-trigger="keyup[key==&#39;Enter&#39;] from:body throttle:1s once consume queue:last target:input"`,
+		},
+		{attr: SwapOOB("", ""), expected: `hx-swap-oob="true"`},
+		{attr: SwapOOB(SwapAfterBegin, "#alerts"), expected: `hx-swap-oob="afterbegin:#alerts"`},
+		{attr: Vals(map[string]any{"page": 2}), expected: `hx-vals="{&#34;page&#34;:2}"`},
+		{attr: Headers(map[string]string{"X-CSRF": "t"}), expected: `hx-headers="{&#34;X-CSRF&#34;:&#34;t&#34;}"`},
+		{attr: Boost(true), expected: `hx-boost="true"`},
+		{attr: Ext("json-enc", "loading-states"), expected: `hx-ext="json-enc,loading-states"`},
+		{attr: On("htmx:after-request", "this.reset()"), expected: `hx-on:htmx:after-request="this.reset()"`},
+		{attr: Preserve(), expected: `hx-preserve="true"`},
+		{attr: HistoryElt(), expected: `hx-history-elt`},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders '%v'", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.attr.Attributes())
+		})
+	}
+
+	t.Run("panics on values JSON can't encode", func(t *testing.T) {
+		assert.PanicsWithValue(t, "htmx: json: unsupported type: func()", func() {
+			Vals(map[string]any{"f": func() {}})
+		})
+	})
+
+	t.Run("returns errors for values JSON can't encode", func(t *testing.T) {
+		attr, err := ValsJSON(map[string]any{"page": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, `hx-vals="{&#34;page&#34;:2}"`, attr.Attributes())
+
+		_, err = ValsJSON(map[string]any{"x": math.NaN()})
+		assert.EqualError(t, err, "json: unsupported value: NaN")
+	})
+}
+
+func TestOOB(t *testing.T) {
+	b := &strings.Builder{}
+	n := &mx.Node{Writer: b}
+	n.Li(nil, mx.Text("new todo"))
+	OOB(SwapInnerHTML, "#count", mx.Text("3 items"))(n)
+	assert.Equal(t, `<li>new todo</li><div hx-swap-oob="innerHTML:#count">3 items</div>`, b.String())
+}
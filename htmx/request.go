@@ -0,0 +1,137 @@
+package htmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type (
+	// Request is what HTMX tells about a request in its headers.
+	Request struct {
+		Enabled        bool   // HX-Request: the request was issued by HTMX
+		Boosted        bool   // HX-Boosted: by an element with hx-boost
+		HistoryRestore bool   // HX-History-Restore-Request: to restore the history after a cache miss
+		Target         string // HX-Target: id of the target element
+		Trigger        string // HX-Trigger: id of the triggered element
+		TriggerName    string // HX-Trigger-Name: name of the triggered element
+		CurrentURL     string // HX-Current-URL: URL of the browser
+		Prompt         string // HX-Prompt: answer to hx-prompt
+	}
+
+	// ResponseHeader sets the response headers read by HTMX. Set them before
+	// writing the body.
+	ResponseHeader struct {
+		header http.Header
+	}
+)
+
+// FromRequest reads the HTMX request headers.
+func FromRequest(r *http.Request) Request {
+	h := r.Header
+	return Request{
+		Enabled:        h.Get("HX-Request") == "true",
+		Boosted:        h.Get("HX-Boosted") == "true",
+		HistoryRestore: h.Get("HX-History-Restore-Request") == "true",
+		Target:         h.Get("HX-Target"),
+		Trigger:        h.Get("HX-Trigger"),
+		TriggerName:    h.Get("HX-Trigger-Name"),
+		CurrentURL:     h.Get("HX-Current-URL"),
+		Prompt:         h.Get("HX-Prompt"),
+	}
+}
+
+// IsRequest checks if a request was issued by HTMX, and so expects a partial
+// response. History restore requests expect the full page.
+func IsRequest(r *http.Request) bool {
+	req := FromRequest(r)
+	return req.Enabled && !req.HistoryRestore
+}
+
+// Response returns the HTMX headers of a response. It adds Vary headers so
+// caches keep full and partial responses apart.
+func Response(w http.ResponseWriter) ResponseHeader {
+	h := w.Header()
+	if !strings.Contains(strings.Join(h.Values("Vary"), ","), "HX-Request") {
+		h.Add("Vary", "HX-Request")
+	}
+	return ResponseHeader{header: h}
+}
+
+// Trigger triggers events on the client when the response is received.
+func (h ResponseHeader) Trigger(events ...string) ResponseHeader {
+	h.header.Set("HX-Trigger", strings.Join(events, ", "))
+	return h
+}
+
+// TriggerDetail triggers events with details, e.g.
+// {"showMessage": {"level": "info", "text": "Saved"}}.
+func (h ResponseHeader) TriggerDetail(events map[string]any) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	h.header.Set("HX-Trigger", string(b))
+	return nil
+}
+
+// TriggerAfterSwap triggers events on the client after the swap.
+func (h ResponseHeader) TriggerAfterSwap(events ...string) ResponseHeader {
+	h.header.Set("HX-Trigger-After-Swap", strings.Join(events, ", "))
+	return h
+}
+
+// TriggerAfterSettle triggers events on the client after the settle.
+func (h ResponseHeader) TriggerAfterSettle(events ...string) ResponseHeader {
+	h.header.Set("HX-Trigger-After-Settle", strings.Join(events, ", "))
+	return h
+}
+
+// Redirect makes the client load url with a full page load.
+func (h ResponseHeader) Redirect(url string) ResponseHeader {
+	h.header.Set("HX-Redirect", url)
+	return h
+}
+
+// Location makes the client load url with an HTMX request, without a full page
+// load.
+func (h ResponseHeader) Location(url string) ResponseHeader {
+	h.header.Set("HX-Location", url)
+	return h
+}
+
+// Refresh makes the client reload the page.
+func (h ResponseHeader) Refresh() ResponseHeader {
+	h.header.Set("HX-Refresh", "true")
+	return h
+}
+
+// PushURL pushes url to the history.
+func (h ResponseHeader) PushURL(url string) ResponseHeader {
+	h.header.Set("HX-Push-Url", url)
+	return h
+}
+
+// ReplaceURL replaces the current URL in the history with url.
+func (h ResponseHeader) ReplaceURL(url string) ResponseHeader {
+	h.header.Set("HX-Replace-Url", url)
+	return h
+}
+
+// Reswap overrides the hx-swap of the element that issued the request.
+func (h ResponseHeader) Reswap(style SwapStyle) ResponseHeader {
+	h.header.Set("HX-Reswap", string(style))
+	return h
+}
+
+// Retarget overrides the hx-target of the element that issued the request.
+func (h ResponseHeader) Retarget(selector string) ResponseHeader {
+	h.header.Set("HX-Retarget", selector)
+	return h
+}
+
+// Reselect overrides the hx-select of the element that issued the request.
+func (h ResponseHeader) Reselect(selector string) ResponseHeader {
+	h.header.Set("HX-Reselect", selector)
+	return h
+}
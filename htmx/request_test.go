@@ -0,0 +1,111 @@
+package htmx
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	requestTestCase struct {
+		headers  map[string]string
+		expected Request
+		partial  bool
+	}
+)
+
+func TestFromRequest(t *testing.T) {
+	testCases := []requestTestCase{
+		{headers: map[string]string{}, expected: Request{}},
+		{
+			headers: map[string]string{
+				"HX-Request":      "true",
+				"HX-Target":       "list",
+				"HX-Trigger":      "save",
+				"HX-Trigger-Name": "save-btn",
+				"HX-Current-URL":  "http://localhost/todos",
+				"HX-Prompt":       "yes",
+			},
+			expected: Request{
+				Enabled:     true,
+				Target:      "list",
+				Trigger:     "save",
+				TriggerName: "save-btn",
+				CurrentURL:  "http://localhost/todos",
+				Prompt:      "yes",
+			},
+			partial: true,
+		},
+		{
+			headers:  map[string]string{"HX-Request": "true", "HX-Boosted": "true"},
+			expected: Request{Enabled: true, Boosted: true},
+			partial:  true,
+		},
+		{
+			headers:  map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			expected: Request{Enabled: true, HistoryRestore: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("reads %v", tc.headers)
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tc.expected, FromRequest(r))
+			assert.Equal(t, tc.partial, IsRequest(r))
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	t.Run("sets headers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		Response(w).
+			Trigger("saved", "refresh").
+			TriggerAfterSwap("swapped").
+			TriggerAfterSettle("settled").
+			PushURL("/todos/1").
+			ReplaceURL("/todos").
+			Reswap(SwapOuterHTML.Transition()).
+			Retarget("#main").
+			Reselect("#content").
+			Redirect("/login").
+			Location("/home").
+			Refresh()
+		assert.Equal(t, http.Header{
+			"Vary":                    {"HX-Request"},
+			"Hx-Trigger":              {"saved, refresh"},
+			"Hx-Trigger-After-Swap":   {"swapped"},
+			"Hx-Trigger-After-Settle": {"settled"},
+			"Hx-Push-Url":             {"/todos/1"},
+			"Hx-Replace-Url":          {"/todos"},
+			"Hx-Reswap":               {"outerHTML transition:true"},
+			"Hx-Retarget":             {"#main"},
+			"Hx-Reselect":             {"#content"},
+			"Hx-Redirect":             {"/login"},
+			"Hx-Location":             {"/home"},
+			"Hx-Refresh":              {"true"},
+		}, w.Header())
+	})
+
+	t.Run("triggers events with details", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := Response(w).TriggerDetail(map[string]any{"showMessage": map[string]string{"text": "Saved"}})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"showMessage":{"text":"Saved"}}`, w.Header().Get("HX-Trigger"))
+	})
+
+	t.Run("varies once", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		w.Header().Add("Vary", "Accept-Encoding")
+		Response(w)
+		Response(w)
+		assert.Equal(t, []string{"Accept-Encoding", "HX-Request"}, w.Header().Values("Vary"))
+	})
+}
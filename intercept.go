@@ -0,0 +1,77 @@
+package mx
+
+// Interceptor changes how elements are rendered, for concerns that cut across
+// components, like adding rel="noopener" to external links or test ids to
+// buttons. All fields are optional. Elements rendered by the functions aren't
+// intercepted, except the children passed to Wrap.
+type Interceptor struct {
+	Attrs  func(tag string, attrs []Attribute) []Attribute                    // rewrites the attributes, with values unescaped
+	Before func(n *Node, tag string, attrs []Attribute)                       // renders content before the element
+	After  func(n *Node, tag string, attrs []Attribute)                       // renders content after the element
+	Wrap   func(n *Node, tag string, attrs []Attribute, children func(*Node)) // renders the children, e.g. inside a wrapper
+}
+
+// intercepting checks if the element about to be rendered must go through
+// Interceptors.
+func (n *Node) intercepting() bool {
+	if n.bypass {
+		n.bypass = false
+		return false
+	}
+	return len(n.Interceptors) > 0 && n.intercepted == 0
+}
+
+// intercept renders an element through Interceptors. URLs written by Attrs are
+// sanitized, unless they are trusted, like those set with U. The first
+// interceptor runs first and wraps the others.
+func (n *Node) intercept(tag string, attr Attr, children []func(*Node)) {
+	var attrs []Attribute
+	if attr != nil {
+		attrs = trustURLs(parseAttributes(attr.Attributes()), attr)
+	}
+	for _, ic := range n.Interceptors {
+		if ic.Attrs != nil {
+			attrs = ic.Attrs(tag, attrs)
+		}
+	}
+
+	content := func(n *Node) {
+		depth := n.intercepted
+		n.intercepted = 0
+		for _, child := range children {
+			if child != nil {
+				child(n)
+			}
+		}
+		n.intercepted = depth
+	}
+	for i := len(n.Interceptors) - 1; i >= 0; i-- {
+		if wrap := n.Interceptors[i].Wrap; wrap != nil {
+			inner := content
+			content = func(n *Node) {
+				n.intercepted++
+				wrap(n, tag, attrs, inner)
+				n.intercepted--
+			}
+		}
+	}
+
+	n.intercepted++
+	for _, ic := range n.Interceptors {
+		if ic.Before != nil {
+			ic.Before(n, tag, attrs)
+		}
+	}
+	n.intercepted--
+
+	n.bypass = true
+	n.el(tag, attrString(sanitizeAttrs(attrs)), content)
+
+	n.intercepted++
+	for i := len(n.Interceptors) - 1; i >= 0; i-- {
+		if after := n.Interceptors[i].After; after != nil {
+			after(n, tag, attrs)
+		}
+	}
+	n.intercepted--
+}
@@ -0,0 +1,173 @@
+package mx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	interceptTestCase struct {
+		name         string
+		interceptors []Interceptor
+		component    func(*Node)
+		expected     string
+	}
+)
+
+// noopener adds rel="noopener" to links to other sites.
+var noopener = Interceptor{
+	Attrs: func(tag string, attrs []Attribute) []Attribute {
+		for _, a := range attrs {
+			if tag == "a" && a.Name == "href" && strings.HasPrefix(a.Value, "https://") {
+				return append(attrs, Attribute{Name: "rel", Value: "noopener"})
+			}
+		}
+		return attrs
+	},
+}
+
+// testIDs adds data-testid to buttons, from their name.
+var testIDs = Interceptor{
+	Attrs: func(tag string, attrs []Attribute) []Attribute {
+		for _, a := range attrs {
+			if tag == "button" && a.Name == "name" {
+				return append(attrs, Attribute{Name: "data-testid", Value: "btn-" + a.Value})
+			}
+		}
+		return attrs
+	},
+}
+
+// labels wraps the children of every li in a span, with a marker around it.
+var labels = Interceptor{
+	Before: func(n *Node, tag string, attrs []Attribute) {
+		if tag == "li" {
+			Raw("<!-- li -->")(n)
+		}
+	},
+	After: func(n *Node, tag string, attrs []Attribute) {
+		if tag == "li" {
+			Raw("<!-- /li -->")(n)
+		}
+	},
+	Wrap: func(n *Node, tag string, attrs []Attribute, children func(*Node)) {
+		if tag != "li" {
+			children(n)
+			return
+		}
+		n.Span(S(`class="label"`), children)
+	},
+}
+
+func interceptedPage(n *Node) {
+	n.Nav(nil, func(n *Node) {
+		n.A(M{"href": "https://example.com"}, Text("Out"))
+		n.A(M{"href": "/home"}, Text("Home"))
+		n.Button(M{"name": "save"}, Text("Save"))
+	})
+}
+
+func TestInterceptors(t *testing.T) {
+	testCases := []interceptTestCase{
+		{
+			name:         "rewrites attributes",
+			interceptors: []Interceptor{noopener, testIDs},
+			component:    interceptedPage,
+			expected: `<nav><a href="https://example.com" rel="noopener">Out</a><a href="/home">Home</a>` +
+				`<button name="save" data-testid="btn-save">Save</button></nav>`,
+		},
+		{
+			name:         "renders around elements and wraps their children",
+			interceptors: []Interceptor{labels},
+			component: func(n *Node) {
+				n.Ul(nil, func(n *Node) {
+					n.Li(nil, Text("a"))
+					n.Li(nil, func(n *Node) {
+						n.Ul(nil, func(n *Node) { n.Li(nil, Text("b")) })
+					})
+				})
+			},
+			expected: `<ul><!-- li --><li><span class="label">a</span></li><!-- /li -->` +
+				`<!-- li --><li><span class="label"><ul><!-- li --><li><span class="label">b</span></li><!-- /li --></ul></span></li><!-- /li --></ul>`,
+		},
+		{
+			name: "wraps in order",
+			interceptors: []Interceptor{
+				{Wrap: func(n *Node, tag string, attrs []Attribute, children func(*Node)) { n.B(nil, children) }},
+				{Wrap: func(n *Node, tag string, attrs []Attribute, children func(*Node)) { n.I(nil, children) }},
+			},
+			component: func(n *Node) { n.P(nil, Text("x")) },
+			expected:  `<p><b><i>x</i></b></p>`,
+		},
+		{
+			name: "doesn't intercept void elements' children",
+			interceptors: []Interceptor{{
+				Wrap: func(n *Node, tag string, attrs []Attribute, children func(*Node)) { n.Span(nil, children) },
+				Attrs: func(tag string, attrs []Attribute) []Attribute {
+					return append(attrs, Attribute{Name: "loading", Value: "lazy"})
+				},
+			}},
+			component: func(n *Node) { n.Img(S(`src="/a.png" alt="a"`)) },
+			expected:  `<img src="/a.png" alt="a" loading="lazy" />`,
+		},
+		{
+			name: "sanitizes rewritten URLs",
+			interceptors: []Interceptor{{
+				Attrs: func(tag string, attrs []Attribute) []Attribute {
+					return []Attribute{{Name: "href", Value: `javascript:go("<x>")`}, {Name: "title", Value: `"<x>"`}}
+				},
+			}},
+			component: func(n *Node) { n.A(M{"href": "/a"}, Text("x")) },
+			expected:  `<a href="#ZgotmplZ" title="&#34;&lt;x&gt;&#34;">x</a>`,
+		},
+		{
+			name: "writes trusted URLs as they are",
+			interceptors: []Interceptor{{
+				Attrs: func(tag string, attrs []Attribute) []Attribute {
+					return append(attrs, Attribute{Name: "formaction", Value: `javascript:go("<x>")`, Trusted: true})
+				},
+			}},
+			component: func(n *Node) { n.Button(S(`type="submit"`), Text("x")) },
+			expected:  `<button type="submit" formaction="javascript:go(&#34;&lt;x&gt;&#34;)">x</button>`,
+		},
+		{
+			name: "keeps URLs set with U trusted",
+			interceptors: []Interceptor{{
+				Attrs: func(tag string, attrs []Attribute) []Attribute { return attrs },
+			}},
+			component: func(n *Node) { n.A(Slice{U{"href": "javascript:go()"}, S(`src="javascript:x"`)}, Text("x")) },
+			expected:  `<a href="javascript:go()" src="#ZgotmplZ">x</a>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &strings.Builder{}
+			n := &Node{Writer: b, Interceptors: tc.interceptors}
+			tc.component(n)
+			assert.NoError(t, Error(n))
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+
+	t.Run("applies to trees", func(t *testing.T) {
+		tree, err := Build(func(n *Node) {
+			n.Interceptors = []Interceptor{noopener}
+			interceptedPage(n)
+		})
+		assert.NoError(t, err)
+		rel, _ := tree.Find("a")[0].Attr("rel")
+		assert.Equal(t, "noopener", rel)
+	})
+
+	t.Run("applies to WrapEach", func(t *testing.T) {
+		b := &strings.Builder{}
+		n := &Node{Writer: b, Interceptors: []Interceptor{testIDs}}
+		WrapEach(n, func(n *Node, content func(*Node)) { n.Div(nil, content) }, func(n *Node) {
+			n.Button(M{"name": "a"}, nil)
+		})
+		assert.Equal(t, `<div><button name="a" data-testid="btn-a"></button></div>`, b.String())
+	})
+}
@@ -0,0 +1,17 @@
+// Package duration formats durations for the attributes of HTMX and Alpine.js,
+// which both read "2s" or "500ms".
+package duration
+
+import (
+	"strconv"
+	"time"
+)
+
+// Format formats d in whole seconds, or in milliseconds if it has a fraction
+// of a second, e.g. "2s" or "1500ms".
+func Format(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
@@ -0,0 +1,32 @@
+package duration
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	formatTestCase struct {
+		d        time.Duration
+		expected string
+	}
+)
+
+func TestFormat(t *testing.T) {
+	testCases := []formatTestCase{
+		{d: 2 * time.Second, expected: "2s"},
+		{d: 1500 * time.Millisecond, expected: "1500ms"},
+		{d: 300 * time.Millisecond, expected: "300ms"},
+		{d: 0, expected: "0s"},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("formats %v", tc.d)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Format(tc.d))
+		})
+	}
+}
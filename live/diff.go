@@ -0,0 +1,121 @@
+package live
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Patch is a change to the DOM of a view. Path is the index of the node in
+// each level of childNodes, starting at the root element of the view.
+type Patch struct {
+	Op     string            `json:"op"` // "html", "replace", "text", "attrs", "append" or "remove"
+	Path   []int             `json:"path"`
+	HTML   string            `json:"html,omitempty"`   // html: all the children; replace: the new node; append: the new children
+	Text   string            `json:"text"`             // text: the new text
+	Set    map[string]string `json:"set,omitempty"`    // attrs: attributes to set
+	Remove []string          `json:"remove,omitempty"` // attrs: attributes to remove
+}
+
+// parse parses the output of a view as the content of its root <div>.
+func parse(s string) (*html.Node, error) {
+	div := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(s), div)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		div.AppendChild(node)
+	}
+	return div, nil
+}
+
+// diff returns the patches that turn the children of old into the children of
+// new. Children are matched by position.
+func diff(old, new *html.Node, path []int) []Patch {
+	var patches []Patch
+	oldChild, newChild := old.FirstChild, new.FirstChild
+	i := 0
+	for ; oldChild != nil && newChild != nil; i++ {
+		patches = append(patches, diffNode(oldChild, newChild, append(slices.Clone(path), i))...)
+		oldChild, newChild = oldChild.NextSibling, newChild.NextSibling
+	}
+
+	// Removing from the end keeps the paths of the previous siblings valid
+	var removed []Patch
+	for ; oldChild != nil; i++ {
+		removed = append(removed, Patch{Op: "remove", Path: append(slices.Clone(path), i)})
+		oldChild = oldChild.NextSibling
+	}
+	slices.Reverse(removed)
+	patches = append(patches, removed...)
+
+	if newChild != nil {
+		patches = append(patches, Patch{Op: "append", Path: slices.Clone(path), HTML: renderNodes(newChild)})
+	}
+	return patches
+}
+
+// renderNodes renders node and its next siblings.
+func renderNodes(node *html.Node) string {
+	b := &strings.Builder{}
+	for ; node != nil; node = node.NextSibling {
+		_ = html.Render(b, node)
+	}
+	return b.String()
+}
+
+// diffNode returns the patches that turn old into new.
+func diffNode(old, new *html.Node, path []int) []Patch {
+	if old.Type != new.Type || old.Type == html.ElementNode && old.Data != new.Data {
+		b := &strings.Builder{}
+		_ = html.Render(b, new)
+		return []Patch{{Op: "replace", Path: path, HTML: b.String()}}
+	}
+
+	switch old.Type {
+	case html.TextNode, html.CommentNode:
+		if old.Data != new.Data {
+			if old.Type == html.CommentNode {
+				return []Patch{{Op: "replace", Path: path, HTML: "<!--" + new.Data + "-->"}}
+			}
+			return []Patch{{Op: "text", Path: path, Text: new.Data}}
+		}
+		return nil
+	case html.ElementNode:
+		var patches []Patch
+		if p, ok := diffAttrs(old, new, path); ok {
+			patches = append(patches, p)
+		}
+		return append(patches, diff(old, new, path)...)
+	}
+	return nil
+}
+
+// diffAttrs returns the patch that turns the attributes of old into those of
+// new, if they differ.
+func diffAttrs(old, new *html.Node, path []int) (Patch, bool) {
+	p := Patch{Op: "attrs", Path: path}
+	oldAttrs := map[string]string{}
+	for _, a := range old.Attr {
+		oldAttrs[a.Key] = a.Val
+	}
+	newAttrs := map[string]bool{}
+	for _, a := range new.Attr {
+		newAttrs[a.Key] = true
+		if v, ok := oldAttrs[a.Key]; !ok || v != a.Val {
+			if p.Set == nil {
+				p.Set = map[string]string{}
+			}
+			p.Set[a.Key] = a.Val
+		}
+	}
+	for _, a := range old.Attr {
+		if !newAttrs[a.Key] {
+			p.Remove = append(p.Remove, a.Key)
+		}
+	}
+	return p, p.Set != nil || p.Remove != nil
+}
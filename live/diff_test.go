@@ -0,0 +1,179 @@
+package live
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type (
+	diffTestCase struct {
+		old      string
+		new      string
+		expected []Patch
+	}
+)
+
+func TestDiff(t *testing.T) {
+	testCases := []diffTestCase{
+		{
+			old: `<p>Count: 1</p>`,
+			new: `<p>Count: 1</p>`,
+		},
+		{
+			old:      `<p>Count: 1</p>`,
+			new:      `<p>Count: 2</p>`,
+			expected: []Patch{{Op: "text", Path: []int{0, 0}, Text: "Count: 2"}},
+		},
+		{
+			old:      `<p>one</p>`,
+			new:      `<p></p>`,
+			expected: []Patch{{Op: "remove", Path: []int{0, 0}}},
+		},
+		{
+			old:      `<p>one</p>`,
+			new:      `<h1>one</h1>`,
+			expected: []Patch{{Op: "replace", Path: []int{0}, HTML: "<h1>one</h1>"}},
+		},
+		{
+			old: `<input type="checkbox" name="done" checked>`,
+			new: `<input type="checkbox" name="todo" class="x">`,
+			expected: []Patch{{
+				Op:     "attrs",
+				Path:   []int{0},
+				Set:    map[string]string{"name": "todo", "class": "x"},
+				Remove: []string{"checked"},
+			}},
+		},
+		{
+			old: `<ul><li>a</li><li>b</li><li>c</li></ul>`,
+			new: `<ul><li>a</li></ul>`,
+			expected: []Patch{
+				{Op: "remove", Path: []int{0, 2}},
+				{Op: "remove", Path: []int{0, 1}},
+			},
+		},
+		{
+			old:      `<ul><li>a</li></ul>`,
+			new:      `<ul><li>a</li><li>b</li><li>c</li></ul>`,
+			expected: []Patch{{Op: "append", Path: []int{0}, HTML: "<li>b</li><li>c</li>"}},
+		},
+		{
+			old:      `<p>a</p><!-- x -->`,
+			new:      `<p>a</p><!-- y -->`,
+			expected: []Patch{{Op: "replace", Path: []int{1}, HTML: "<!-- y -->"}},
+		},
+		{
+			old: `<p>1</p>`,
+			new: `<table><tr><td>2</td></tr></table>`,
+			expected: []Patch{
+				{Op: "replace", Path: []int{0}, HTML: "<table><tbody><tr><td>2</td></tr></tbody></table>"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("diffs '%v' and '%v'", tc.old, tc.new)
+		t.Run(name, func(t *testing.T) {
+			old, err := parse(tc.old)
+			assert.NoError(t, err)
+			new, err := parse(tc.new)
+			assert.NoError(t, err)
+
+			patches := diff(old, new, []int{})
+			assert.Equal(t, tc.expected, patches)
+
+			apply(t, old, patches)
+			assert.Equal(t, render(t, new), render(t, old))
+		})
+	}
+}
+
+// apply applies patches to root the way the browser script does.
+func apply(t *testing.T, root *html.Node, patches []Patch) {
+	t.Helper()
+	for _, p := range patches {
+		n := root
+		for _, i := range p.Path {
+			n = child(n, i)
+		}
+		switch p.Op {
+		case "html":
+			for n.FirstChild != nil {
+				n.RemoveChild(n.FirstChild)
+			}
+			for _, c := range fragment(t, p.HTML) {
+				n.AppendChild(c)
+			}
+		case "replace":
+			for _, c := range fragment(t, p.HTML) {
+				n.Parent.InsertBefore(c, n)
+			}
+			n.Parent.RemoveChild(n)
+		case "text":
+			n.Data = p.Text
+		case "append":
+			for _, c := range fragment(t, p.HTML) {
+				n.AppendChild(c)
+			}
+		case "remove":
+			n.Parent.RemoveChild(n)
+		case "attrs":
+			var attrs []html.Attribute
+			for _, a := range n.Attr {
+				if v, ok := p.Set[a.Key]; ok {
+					a.Val = v
+					delete(p.Set, a.Key)
+				}
+				if !strings.Contains(" "+strings.Join(p.Remove, " ")+" ", " "+a.Key+" ") {
+					attrs = append(attrs, a)
+				}
+			}
+			for k, v := range p.Set {
+				attrs = append(attrs, html.Attribute{Key: k, Val: v})
+			}
+			n.Attr = attrs
+		}
+	}
+}
+
+// child returns the ith child of n.
+func child(n *html.Node, i int) *html.Node {
+	c := n.FirstChild
+	for ; i > 0; i-- {
+		c = c.NextSibling
+	}
+	return c
+}
+
+// fragment parses s in a <template>, as the browser script does.
+func fragment(t *testing.T, s string) []*html.Node {
+	t.Helper()
+	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "template", DataAtom: atom.Template})
+	assert.NoError(t, err)
+	return nodes
+}
+
+// render renders the children of n, with attributes sorted.
+func render(t *testing.T, n *html.Node) string {
+	t.Helper()
+	b := &strings.Builder{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sortAttrs(c)
+		assert.NoError(t, html.Render(b, c))
+	}
+	return b.String()
+}
+
+// sortAttrs sorts the attributes of n and its descendants.
+func sortAttrs(n *html.Node) {
+	slices.SortFunc(n.Attr, func(a, b html.Attribute) int { return strings.Compare(a.Key, b.Key) })
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sortAttrs(c)
+	}
+}
@@ -0,0 +1,342 @@
+// Package live runs server-driven views: a view keeps its state on the
+// server, the browser sends it events over a WebSocket, and after each event
+// the view is rendered again and only the changes are sent back as DOM
+// patches. No JavaScript has to be written for the page.
+//
+//	type Counter struct{ count int }
+//
+//	func (c *Counter) Render(n *mx.Node) {
+//		n.P(nil, mx.Textf("Count: %d", c.count))
+//		n.Button(live.Click("inc"), mx.Text("+1"))
+//	}
+//
+//	func (c *Counter) HandleEvent(e live.Event) error {
+//		if e.Name == "inc" {
+//			c.count++
+//		}
+//		return nil
+//	}
+//
+//	http.Handle("/counter", &live.Handler{
+//		View: func(r *http.Request) (live.View, error) { return &Counter{}, nil },
+//	})
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/websocket"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// View is a stateful component.
+	View interface {
+		Render(n *mx.Node)
+		HandleEvent(e Event) error
+	}
+
+	// Mounter is implemented by views that change without browser events, e.g.
+	// on timers. Mount is called when the session starts; call Session.Update
+	// to change the state and stop when Session.Done is closed.
+	Mounter interface {
+		Mount(s *Session)
+	}
+
+	// Event is an event sent by the browser.
+	Event struct {
+		Name   string            `json:"event"`
+		Values map[string]string `json:"values"` // data-live-value-* attributes, form fields or input value
+	}
+
+	// Session is a view connected to a browser.
+	Session struct {
+		view      View
+		transport mx.Transport
+		tree      *html.Node
+		mu        sync.Mutex
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// Handler serves a view: the page with the first render for GET requests,
+	// and a session for WebSocket connections from that page.
+	Handler struct {
+		View    func(r *http.Request) (View, error)         // creates the view for a request
+		Layout  func(content func(*mx.Node)) func(*mx.Node) // wraps the view in a page; a blank page if nil
+		OnError func(error)                                 // reports the errors of events, and those that end sessions
+	}
+)
+
+// Applies the patches received from the server and sends the events
+const script = `(function () {
+  var root = document.currentScript.previousElementSibling;
+  var url = (location.protocol === "https:" ? "wss://" : "ws://") + location.host + location.pathname + location.search;
+  var ws;
+  function connect() {
+    ws = new WebSocket(url);
+    ws.onmessage = function (m) { JSON.parse(m.data).forEach(apply); };
+    ws.onclose = function () { setTimeout(connect, 1000); };
+  }
+  function find(path) {
+    var n = root;
+    for (var i = 0; i < path.length; i++) n = n.childNodes[path[i]];
+    return n;
+  }
+  function nodes(html) {
+    var t = document.createElement("template");
+    t.innerHTML = html;
+    return t.content;
+  }
+  function apply(p) {
+    var n = find(p.path), k;
+    switch (p.op) {
+    case "html": n.replaceChildren(nodes(p.html)); break;
+    case "replace": n.replaceWith(nodes(p.html)); break;
+    case "text": n.data = p.text; break;
+    case "append": n.append(nodes(p.html)); break;
+    case "remove": n.remove(); break;
+    case "attrs":
+      for (k in p.set || {}) {
+        n.setAttribute(k, p.set[k]);
+        if (k === "value" && "value" in n) n.value = p.set[k];
+        if (k === "checked") n.checked = true;
+      }
+      (p.remove || []).forEach(function (k) {
+        n.removeAttribute(k);
+        if (k === "checked") n.checked = false;
+      });
+    }
+  }
+  function values(el) {
+    var v = {};
+    for (var i = 0; i < el.attributes.length; i++) {
+      var a = el.attributes[i];
+      if (a.name.indexOf("data-live-value-") === 0) v[a.name.slice(16)] = a.value;
+    }
+    return v;
+  }
+  function send(name, v) {
+    if (ws && ws.readyState === 1) ws.send(JSON.stringify({event: name, values: v}));
+  }
+  root.addEventListener("click", function (e) {
+    var el = e.target.closest("[data-live-click]");
+    if (!el || !root.contains(el)) return;
+    e.preventDefault();
+    send(el.getAttribute("data-live-click"), values(el));
+  });
+  root.addEventListener("submit", function (e) {
+    var name = e.target.getAttribute("data-live-submit");
+    if (!name) return;
+    e.preventDefault();
+    var v = values(e.target);
+    new FormData(e.target).forEach(function (val, k) { v[k] = String(val); });
+    send(name, v);
+  });
+  ["change", "input"].forEach(function (type) {
+    root.addEventListener(type, function (e) {
+      var el = e.target, name = el.getAttribute("data-live-" + type);
+      if (!name) return;
+      var v = values(el);
+      v[el.name || "value"] = el.type === "checkbox" ? String(el.checked) : el.value;
+      send(name, v);
+    });
+  });
+  connect();
+})();`
+
+// Click sends the event when the element is clicked.
+func Click(event string) mx.Attr { return mx.M{"data-live-click": event} }
+
+// Submit sends the event with the form fields when the form is submitted.
+func Submit(event string) mx.Attr { return mx.M{"data-live-submit": event} }
+
+// Change sends the event with the input's value when it changes.
+func Change(event string) mx.Attr { return mx.M{"data-live-change": event} }
+
+// Input sends the event with the input's value on every keystroke.
+func Input(event string) mx.Attr { return mx.M{"data-live-input": event} }
+
+// Value adds a value to the events sent by the element.
+func Value(name, value string) mx.Attr { return mx.M{"data-live-value-" + name: value} }
+
+// NewSession renders a view for the first time. The patches of later renders
+// are sent through t as JSON arrays, unless t is nil, after a first patch that
+// replaces the content of the page with the first render, so a page that was
+// rendered differently, or by an earlier session, is in sync. If the view is a
+// Mounter, it is mounted.
+func NewSession(v View, t mx.Transport) (*Session, error) {
+	s := &Session{view: v, transport: t, done: make(chan struct{})}
+	tree, err := s.render()
+	if err != nil {
+		return nil, err
+	}
+	s.tree = tree
+	if err := s.send([]Patch{{Op: "html", Path: []int{}, HTML: renderNodes(tree.FirstChild)}}); err != nil {
+		return nil, err
+	}
+	if m, ok := v.(Mounter); ok {
+		m.Mount(s)
+	}
+	return s, nil
+}
+
+// Dispatch handles a browser event and returns the patches sent.
+func (s *Session) Dispatch(e Event) ([]Patch, error) {
+	var err error
+	patches, updateErr := s.Update(func() {
+		err = s.view.HandleEvent(e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patches, updateErr
+}
+
+// Update runs change, which changes the state of the view, renders the view
+// and sends the patches to the browser. It is safe for concurrent use, and
+// the view is only accessed by one change or render at a time.
+func (s *Session) Update(change func()) ([]Patch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change()
+	tree, err := s.render()
+	if err != nil {
+		return nil, err
+	}
+	patches := diff(s.tree, tree, []int{})
+	s.tree = tree
+	return patches, s.send(patches)
+}
+
+// send sends patches to the browser.
+func (s *Session) send(patches []Patch) error {
+	if len(patches) == 0 || s.transport == nil {
+		return nil
+	}
+	msg, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+	return s.transport.Send(msg)
+}
+
+// Done is closed when the session ends.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close ends the session.
+func (s *Session) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// render renders the view into a tree.
+func (s *Session) render() (*html.Node, error) {
+	b := &strings.Builder{}
+	n := &mx.Node{Writer: b}
+	s.view.Render(n)
+	if err := mx.Error(n); err != nil {
+		return nil, err
+	}
+	return parse(b.String())
+}
+
+// ServeHTTP serves the page, or a session for WebSocket connections.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handshake: sameOrigin, Handler: h.serveSession}.ServeHTTP(w, r)
+		return
+	}
+
+	v, err := h.View(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	content := func(n *mx.Node) {
+		n.Div(mx.S("data-mx-live"), v.Render)
+		n.Script(nil, mx.Raw(script))
+	}
+	page := h.Layout
+	if page == nil {
+		page = blankPage
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	n := &mx.Node{Writer: w}
+	page(content)(n)
+}
+
+// serveSession runs a session until the connection closes.
+func (h *Handler) serveSession(ws *websocket.Conn) {
+	err := h.runSession(ws)
+	if err != nil && h.OnError != nil {
+		h.OnError(err)
+	}
+}
+
+// runSession dispatches the events received on ws. The errors of events are
+// reported and the session goes on; render and transport errors end it.
+func (h *Handler) runSession(ws *websocket.Conn) error {
+	defer ws.Close()
+	v, err := h.View(ws.Request())
+	if err != nil {
+		return err
+	}
+	s, err := NewSession(v, mx.TransportFunc(func(msg []byte) error {
+		return websocket.Message.Send(ws, string(msg))
+	}))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	for {
+		var e Event
+		if err := websocket.JSON.Receive(ws, &e); err != nil {
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var eventErr error
+		if _, err := s.Update(func() { eventErr = v.HandleEvent(e) }); err != nil {
+			return err
+		}
+		if eventErr != nil && h.OnError != nil {
+			h.OnError(eventErr)
+		}
+	}
+}
+
+// sameOrigin rejects WebSocket connections from other sites.
+func sameOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	if err != nil || origin.Host != r.Host {
+		return errors.New("live: cross-origin connection")
+	}
+	config.Origin = origin
+	return nil
+}
+
+// blankPage wraps content in a minimal document.
+func blankPage(content func(*mx.Node)) func(*mx.Node) {
+	return func(n *mx.Node) {
+		n.DocType()
+		n.HTML(mx.S(`lang="en"`), func(n *mx.Node) {
+			n.Head(nil, func(n *mx.Node) {
+				n.Meta(mx.S(`charset="utf-8"`))
+			})
+			n.Body(nil, content)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package live
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	counter struct {
+		count   int
+		updates chan func()
+	}
+
+	attrTestCase struct {
+		attr     mx.Attr
+		expected string
+	}
+)
+
+var errUnknownEvent = errors.New("unknown event")
+
+// The first message of a session with a counter
+const initialMessage = `[{"op":"html","path":[],"html":"\u003cp\u003eCount: 0\u003c/p\u003e` +
+	`\u003cbutton data-live-click=\"add\" data-live-value-by=\"2\"\u003e+2\u003c/button\u003e","text":""}]`
+
+func (c *counter) Render(n *mx.Node) {
+	n.P(nil, mx.Textf("Count: %d", c.count))
+	n.Button(mx.Slice{Click("add"), Value("by", "2")}, mx.Text("+2"))
+}
+
+func (c *counter) HandleEvent(e Event) error {
+	if e.Name != "add" {
+		return errUnknownEvent
+	}
+	var by int
+	fmt.Sscan(e.Values["by"], &by)
+	c.count += by
+	return nil
+}
+
+func (c *counter) Mount(s *Session) {
+	if c.updates == nil {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-s.Done():
+				return
+			case change := <-c.updates:
+				s.Update(change)
+			}
+		}
+	}()
+}
+
+func TestAttrs(t *testing.T) {
+	testCases := []attrTestCase{
+		{attr: Click("save"), expected: `data-live-click="save"`},
+		{attr: Submit("create"), expected: `data-live-submit="create"`},
+		{attr: Change("filter"), expected: `data-live-change="filter"`},
+		{attr: Input("search"), expected: `data-live-input="search"`},
+		{attr: Value("id", "7"), expected: `data-live-value-id="7"`},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders '%v'", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.attr.Attributes())
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	t.Run("sends the patches of an event", func(t *testing.T) {
+		tr := &mx.MemoryTransport{}
+		s, err := NewSession(&counter{}, tr)
+		assert.NoError(t, err)
+
+		patches, err := s.Dispatch(Event{Name: "add", Values: map[string]string{"by": "2"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []Patch{{Op: "text", Path: []int{0, 0}, Text: "Count: 2"}}, patches)
+		assert.Equal(t, []string{initialMessage, `[{"op":"text","path":[0,0],"text":"Count: 2"}]`}, messages(tr))
+	})
+
+	t.Run("sends nothing when nothing changes", func(t *testing.T) {
+		tr := &mx.MemoryTransport{}
+		s, err := NewSession(&counter{}, tr)
+		assert.NoError(t, err)
+
+		patches, err := s.Dispatch(Event{Name: "add", Values: map[string]string{"by": "0"}})
+		assert.NoError(t, err)
+		assert.Empty(t, patches)
+		assert.Equal(t, []string{initialMessage}, messages(tr))
+	})
+
+	t.Run("returns the errors of events", func(t *testing.T) {
+		s, err := NewSession(&counter{}, nil)
+		assert.NoError(t, err)
+
+		_, err = s.Dispatch(Event{Name: "remove"})
+		assert.Equal(t, errUnknownEvent, err)
+	})
+
+	t.Run("sends the patches of updates by mounted views", func(t *testing.T) {
+		tr := &mx.MemoryTransport{}
+		c := &counter{updates: make(chan func())}
+		s, err := NewSession(c, tr)
+		assert.NoError(t, err)
+		defer s.Close()
+
+		c.updates <- func() { c.count = 5 }
+		assert.Eventually(t, func() bool { return len(tr.Messages()) == 2 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{initialMessage, `[{"op":"text","path":[0,0],"text":"Count: 5"}]`}, messages(tr))
+	})
+}
+
+func TestHandler(t *testing.T) {
+	errs := make(chan error, 1)
+	srv := httptest.NewServer(&Handler{
+		View:    func(r *http.Request) (View, error) { return &counter{}, nil },
+		OnError: func(err error) { errs <- err },
+	})
+	defer srv.Close()
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	t.Run("renders the page", func(t *testing.T) {
+		res, err := http.Get(srv.URL)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.Contains(t, string(body), `<div data-mx-live><p>Count: 0</p><button data-live-click="add" data-live-value-by="2">+2</button></div><script>(function () {`)
+	})
+
+	t.Run("sends patches for events", func(t *testing.T) {
+		ws, err := websocket.Dial(wsURL, "", srv.URL)
+		assert.NoError(t, err)
+		defer ws.Close()
+		receive(t, ws)
+
+		for _, expected := range []string{"Count: 2", "Count: 4"} {
+			assert.NoError(t, websocket.JSON.Send(ws, Event{Name: "add", Values: map[string]string{"by": "2"}}))
+			assert.Equal(t, []Patch{{Op: "text", Path: []int{0, 0}, Text: expected}}, receive(t, ws))
+		}
+	})
+
+	t.Run("syncs the page when reconnecting", func(t *testing.T) {
+		page, err := parse(`<p>Count: 0</p><button data-live-click="add" data-live-value-by="2">+2</button>`)
+		assert.NoError(t, err)
+
+		ws, err := websocket.Dial(wsURL, "", srv.URL)
+		assert.NoError(t, err)
+		apply(t, page, receive(t, ws))
+		assert.NoError(t, websocket.JSON.Send(ws, Event{Name: "add", Values: map[string]string{"by": "2"}}))
+		apply(t, page, receive(t, ws))
+		assert.Equal(t, "Count: 2", page.FirstChild.FirstChild.Data)
+		ws.Close()
+
+		// The new session starts from a new view, with Count: 0
+		ws, err = websocket.Dial(wsURL, "", srv.URL)
+		assert.NoError(t, err)
+		defer ws.Close()
+		apply(t, page, receive(t, ws))
+		assert.Equal(t, "Count: 0", page.FirstChild.FirstChild.Data)
+		assert.NoError(t, websocket.JSON.Send(ws, Event{Name: "add", Values: map[string]string{"by": "2"}}))
+		apply(t, page, receive(t, ws))
+		assert.Equal(t, "Count: 2", page.FirstChild.FirstChild.Data)
+	})
+
+	t.Run("reports the errors of events and keeps the session", func(t *testing.T) {
+		ws, err := websocket.Dial(wsURL, "", srv.URL)
+		assert.NoError(t, err)
+		defer ws.Close()
+		receive(t, ws)
+
+		assert.NoError(t, websocket.JSON.Send(ws, Event{Name: "remove"}))
+		assert.Equal(t, errUnknownEvent, <-errs)
+		assert.NoError(t, websocket.JSON.Send(ws, Event{Name: "add", Values: map[string]string{"by": "2"}}))
+		assert.Equal(t, []Patch{{Op: "text", Path: []int{0, 0}, Text: "Count: 2"}}, receive(t, ws))
+	})
+
+	t.Run("rejects other origins", func(t *testing.T) {
+		_, err := websocket.Dial(wsURL, "", "http://example.com")
+		assert.Error(t, err)
+	})
+}
+
+// receive receives patches from ws.
+func receive(t *testing.T, ws *websocket.Conn) []Patch {
+	t.Helper()
+	var patches []Patch
+	assert.NoError(t, websocket.JSON.Receive(ws, &patches))
+	return patches
+}
+
+// messages returns the messages sent through tr as strings.
+func messages(tr *mx.MemoryTransport) []string {
+	var msgs []string
+	for _, msg := range tr.Messages() {
+		msgs = append(msgs, string(msg))
+	}
+	return msgs
+}
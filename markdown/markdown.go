@@ -0,0 +1,377 @@
+// Package markdown renders CommonMark documents, with tables, strikethrough,
+// task lists and footnotes, through mx elements. Since the output goes through
+// mx.Node, text is escaped by mx, DevMode pretty printing applies, and each node
+// type can be rendered by a custom component.
+package markdown
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// RenderFunc renders a Markdown node. children renders the node's children
+	// with the renderer's own rules.
+	RenderFunc func(n *mx.Node, node ast.Node, source []byte, children func(*mx.Node))
+
+	// Renderer renders Markdown documents.
+	Renderer struct {
+		Renderers map[ast.NodeKind]RenderFunc // custom components by node kind
+		Policy    *mx.Policy                  // sanitizes raw HTML; nil omits it
+		parser    parser.Parser               // parses the documents
+	}
+)
+
+// New creates a renderer for CommonMark with the GFM extensions and footnotes.
+// Headings get an "id" attribute generated from their text.
+func New() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+	return &Renderer{
+		Renderers: map[ast.NodeKind]RenderFunc{},
+		parser:    md.Parser(),
+	}
+}
+
+// Render renders Markdown with the default renderer.
+func Render(source string) func(*mx.Node) {
+	return New().Render([]byte(source))
+}
+
+// Render parses source and renders it.
+func (r *Renderer) Render(source []byte) func(*mx.Node) {
+	doc := r.parser.Parse(text.NewReader(source))
+	return func(n *mx.Node) {
+		r.render(n, doc, source)
+	}
+}
+
+// PlainText returns the unescaped text content of node, e.g. for building
+// heading anchors or image alt texts.
+func PlainText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	ast.Walk(node, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			b.WriteString(textValue(c, source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(c.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// render renders node with its custom component, if any, or the default one.
+func (r *Renderer) render(n *mx.Node, node ast.Node, source []byte) {
+	children := func(n *mx.Node) {
+		for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+			r.render(n, c, source)
+		}
+	}
+	if r.Policy != nil && r.Renderers[ast.KindRawHTML] == nil && hasRawHTML(node) {
+		children = r.sanitized(node, source)
+	}
+	if fn, ok := r.Renderers[node.Kind()]; ok {
+		fn(n, node, source, children)
+		return
+	}
+
+	switch node := node.(type) {
+	case *ast.Paragraph:
+		n.P(nil, children)
+	case *ast.Heading:
+		id, _ := node.AttributeString("id")
+		heading(n, node.Level, attrs("id", toString(id)), children)
+	case *ast.ThematicBreak:
+		n.Hr(nil)
+	case *ast.Blockquote:
+		n.BlockQuote(nil, children)
+	case *ast.List:
+		if !node.IsOrdered() {
+			n.Ul(nil, children)
+		} else if node.Start != 1 {
+			n.Ol(attrs("start", strconv.Itoa(node.Start)), children)
+		} else {
+			n.Ol(nil, children)
+		}
+	case *ast.ListItem:
+		n.Li(nil, children)
+	case *ast.CodeBlock:
+		n.Pre(nil, func(n *mx.Node) {
+			n.Code(nil, mx.Text(lines(node, source)))
+		})
+	case *ast.FencedCodeBlock:
+		var attr mx.Attr
+		if lang := node.Language(source); lang != nil {
+			attr = attrs("class", "language-"+string(lang))
+		}
+		n.Pre(nil, func(n *mx.Node) {
+			n.Code(attr, mx.Text(lines(node, source)))
+		})
+	case *ast.HTMLBlock:
+		if r.Policy != nil {
+			html := lines(node, source)
+			if node.HasClosure() {
+				html += string(node.ClosureLine.Value(source))
+			}
+			mx.Sanitize(r.Policy, html)(n)
+		}
+	case *ast.RawHTML:
+		// Sanitized by the parent's children with Policy, omitted without it
+	case *ast.Text:
+		mx.Text(textValue(node, source))(n)
+		if node.HardLineBreak() {
+			n.Br(nil)
+		} else if node.SoftLineBreak() {
+			mx.Text("\n")(n)
+		}
+	case *ast.String:
+		if node.IsCode() || node.IsRaw() {
+			mx.Text(string(node.Value))(n)
+		} else {
+			mx.Text(unescape(node.Value))(n)
+		}
+	case *ast.CodeSpan:
+		n.Code(nil, mx.Text(codeSpan(node, source)))
+	case *ast.Emphasis:
+		if node.Level == 2 {
+			n.Strong(nil, children)
+		} else {
+			n.Em(nil, children)
+		}
+	case *ast.Link:
+		href := string(util.URLEscape(node.Destination, true))
+		n.A(attrs("href", href, "title", string(node.Title)), children)
+	case *ast.AutoLink:
+		href := string(util.URLEscape(node.URL(source), false))
+		if node.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(strings.ToLower(href), "mailto:") {
+			href = "mailto:" + href
+		}
+		n.A(attrs("href", href), mx.Text(string(node.Label(source))))
+	case *ast.Image:
+		src := string(util.URLEscape(node.Destination, true))
+		n.Img(attrs("src", src, "alt", PlainText(node, source), "title", string(node.Title)))
+	case *east.Strikethrough:
+		n.Del(nil, children)
+	case *east.TaskCheckBox:
+		if node.IsChecked {
+			n.Input(mx.S(`type="checkbox" checked disabled`))
+		} else {
+			n.Input(mx.S(`type="checkbox" disabled`))
+		}
+	case *east.Table:
+		r.table(n, node, source)
+	case *east.TableHeader:
+		n.THead(nil, func(n *mx.Node) {
+			n.Tr(nil, children)
+		})
+	case *east.TableRow:
+		n.Tr(nil, children)
+	case *east.TableCell:
+		var attr mx.Attr
+		if node.Alignment != east.AlignNone {
+			attr = attrs("style", "text-align: "+node.Alignment.String())
+		}
+		if node.Parent().Kind() == east.KindTableHeader {
+			n.Th(attr, children)
+		} else {
+			n.Td(attr, children)
+		}
+	case *east.FootnoteLink:
+		i := strconv.Itoa(node.Index)
+		id := "fnref:" + i
+		if node.RefIndex > 0 {
+			id = "fnref" + strconv.Itoa(node.RefIndex) + ":" + i
+		}
+		n.Sup(attrs("id", id), func(n *mx.Node) {
+			n.A(attrs("href", "#fn:"+i, "class", "footnote-ref", "role", "doc-noteref"), mx.Text(i))
+		})
+	case *east.FootnoteBacklink:
+		i := strconv.Itoa(node.Index)
+		ref := "#fnref:" + i
+		if node.RefIndex > 0 {
+			ref = "#fnref" + strconv.Itoa(node.RefIndex) + ":" + i
+		}
+		mx.Text("\u00a0")(n)
+		n.A(attrs("href", ref, "class", "footnote-backref", "role", "doc-backlink"), mx.Text("↩︎"))
+	case *east.FootnoteList:
+		n.Div(attrs("class", "footnotes", "role", "doc-endnotes"), func(n *mx.Node) {
+			n.Hr(nil)
+			n.Ol(nil, children)
+		})
+	case *east.Footnote:
+		n.Li(attrs("id", "fn:"+strconv.Itoa(node.Index)), children)
+	default:
+		// Document, TextBlock (tight list items) and unknown extension nodes
+		// render just their children.
+		children(n)
+	}
+}
+
+// sanitized renders the children of node through the policy. The raw HTML is
+// sanitized in one pass, so tags opened and closed in different raw HTML
+// nodes, e.g. "<em>a</em>", are kept together, and the other children are
+// rendered between them as they are.
+func (r *Renderer) sanitized(node ast.Node, source []byte) func(*mx.Node) {
+	var untrusted []string
+	var trusted []func(*mx.Node)
+	raw := &strings.Builder{}
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == ast.KindRawHTML {
+			raw.WriteString(rawHTML(c.(*ast.RawHTML), source))
+			continue
+		}
+		untrusted = append(untrusted, raw.String())
+		raw.Reset()
+		trusted = append(trusted, func(n *mx.Node) { r.render(n, c, source) })
+	}
+	untrusted = append(untrusted, raw.String())
+	return mx.SanitizeParts(r.Policy, untrusted, trusted)
+}
+
+// hasRawHTML checks if node has raw HTML children.
+func hasRawHTML(node ast.Node) bool {
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() == ast.KindRawHTML {
+			return true
+		}
+	}
+	return false
+}
+
+// rawHTML returns the source of an inline HTML node.
+func rawHTML(node *ast.RawHTML, source []byte) string {
+	var b strings.Builder
+	for i := 0; i < node.Segments.Len(); i++ {
+		segment := node.Segments.At(i)
+		b.Write(segment.Value(source))
+	}
+	return b.String()
+}
+
+// table renders the header in <thead> and the remaining rows in <tbody>.
+func (r *Renderer) table(n *mx.Node, node *east.Table, source []byte) {
+	n.Table(nil, func(n *mx.Node) {
+		c := node.FirstChild()
+		if c != nil && c.Kind() == east.KindTableHeader {
+			r.render(n, c, source)
+			c = c.NextSibling()
+		}
+		if c == nil {
+			return
+		}
+		n.TBody(nil, func(n *mx.Node) {
+			for ; c != nil; c = c.NextSibling() {
+				r.render(n, c, source)
+			}
+		})
+	})
+}
+
+// heading renders <h1> to <h6>.
+func heading(n *mx.Node, level int, attr mx.Attr, children ...func(*mx.Node)) {
+	switch level {
+	case 1:
+		n.H1(attr, children...)
+	case 2:
+		n.H2(attr, children...)
+	case 3:
+		n.H3(attr, children...)
+	case 4:
+		n.H4(attr, children...)
+	case 5:
+		n.H5(attr, children...)
+	default:
+		n.H6(attr, children...)
+	}
+}
+
+// attrs builds attributes from key-value pairs, in order, skipping empty values.
+func attrs(kv ...string) mx.Attr {
+	s := mx.Slice{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			s = append(s, mx.M{kv[i]: kv[i+1]})
+		}
+	}
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// lines joins the raw lines of a block node.
+func lines(node ast.Node, source []byte) string {
+	var b strings.Builder
+	for i := 0; i < node.Lines().Len(); i++ {
+		line := node.Lines().At(i)
+		b.Write(line.Value(source))
+	}
+	return b.String()
+}
+
+// codeSpan returns the content of an inline code span, with line endings
+// turned into spaces.
+func codeSpan(node *ast.CodeSpan, source []byte) string {
+	var b strings.Builder
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		switch c := c.(type) {
+		case *ast.Text:
+			value := c.Segment.Value(source)
+			if bytes.HasSuffix(value, []byte("\n")) {
+				value = append(value[:len(value)-1:len(value)-1], ' ')
+			}
+			b.Write(value)
+		case *ast.String:
+			b.Write(c.Value)
+		}
+	}
+	return b.String()
+}
+
+// textValue returns the unescaped value of a text node.
+func textValue(node *ast.Text, source []byte) string {
+	value := node.Segment.Value(source)
+	if node.IsRaw() {
+		return string(value)
+	}
+	return unescape(value)
+}
+
+// unescape resolves backslash escapes and character references.
+func unescape(value []byte) string {
+	value = util.UnescapePunctuations(value)
+	value = util.ResolveNumericReferences(value)
+	return string(util.ResolveEntityNames(value))
+}
+
+// toString converts attribute values set by the parser.
+func toString(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	markdownTestCase struct {
+		source   string
+		expected string
+	}
+)
+
+func TestRender(t *testing.T) {
+	testCases := []markdownTestCase{
+		{source: "Hello, *world* & **you**", expected: "<p>Hello, <em>world</em> &amp; <strong>you</strong></p>"},
+		{source: "## Title", expected: `<h2 id="title">Title</h2>`},
+		{source: "a\\*b &copy; &#35;", expected: "<p>a*b © #</p>"},
+		{source: "<b>raw</b> html", expected: "<p>raw html</p>"},
+		{source: "[x](javascript:alert(1))", expected: `<p><a href="#ZgotmplZ">x</a></p>`},
+		{source: `[x](/a "T")`, expected: `<p><a href="/a" title="T">x</a></p>`},
+		{source: "<me@example.com>", expected: `<p><a href="mailto:me@example.com">me@example.com</a></p>`},
+		{source: "![a *b*](/i.png)", expected: `<p><img src="/i.png" alt="a b" /></p>`},
+		{source: "`a <b>`", expected: "<p><code>a &lt;b&gt;</code></p>"},
+		{source: "a  \nb", expected: "<p>a<br />b</p>"},
+		{source: "~~gone~~", expected: "<p><del>gone</del></p>"},
+		{source: "- a\n- b", expected: "<ul><li>a</li><li>b</li></ul>"},
+		{source: "3. a\n4. b", expected: `<ol start="3"><li>a</li><li>b</li></ol>`},
+		{source: "- [x] done", expected: `<ul><li><input type="checkbox" checked disabled />done</li></ul>`},
+		{source: "> q", expected: "<blockquote><p>q</p></blockquote>"},
+		{source: "---", expected: "<hr />"},
+		{source: "```go\nx := \"<\"\n```", expected: `<pre><code class="language-go">x := &#34;&lt;&#34;` + "\n</code></pre>"},
+		{
+			source:   "| a | b |\n|:-:|---|\n| 1 | 2 |",
+			expected: `<table><thead><tr><th style="text-align: center">a</th><th>b</th></tr></thead><tbody><tr><td style="text-align: center">1</td><td>2</td></tr></tbody></table>`,
+		},
+		{
+			source: "x[^1]\n\n[^1]: note",
+			expected: `<p>x<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup></p>` +
+				`<div class="footnotes" role="doc-endnotes"><hr /><ol><li id="fn:1"><p>note` + "\u00a0" +
+				`<a href="#fnref:1" class="footnote-backref" role="doc-backlink">↩︎</a></p></li></ol></div>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders: %v", tc.source)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			Render(tc.source)(&mx.Node{Writer: b})
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	t.Run("custom renderers", func(t *testing.T) {
+		r := New()
+		r.Renderers[ast.KindHeading] = func(n *mx.Node, node ast.Node, source []byte, children func(*mx.Node)) {
+			id, _ := node.AttributeString("id")
+			n.H2(mx.M{"id": string(id.([]byte))}, children, func(n *mx.Node) {
+				n.A(mx.M{"href": "#" + string(id.([]byte))}, mx.Text("#"))
+			})
+		}
+		b := &strings.Builder{}
+		r.Render([]byte("# A *b*"))(&mx.Node{Writer: b})
+		assert.Equal(t, `<h2 id="a-b">A <em>b</em><a href="#a-b">#</a></h2>`, b.String())
+	})
+
+	t.Run("sanitized raw HTML", func(t *testing.T) {
+		r := New()
+		r.Policy = mx.UGCPolicy()
+		b := &strings.Builder{}
+		r.Render([]byte("<div onclick=\"x\">hi<script>alert(1)</script></div>"))(&mx.Node{Writer: b})
+		assert.Equal(t, "<div>hi</div>", b.String())
+	})
+
+	t.Run("sanitized inline HTML", func(t *testing.T) {
+		testCases := []markdownTestCase{
+			{source: "foo <em>bar</em> baz", expected: "<p>foo <em>bar</em> baz</p>"},
+			{source: "a <b>*x* & y</b>", expected: "<p>a <b><em>x</em> &amp; y</b></p>"},
+			{source: "a <span onclick=\"x\">&lt;b&gt;</span>", expected: "<p>a <span>&lt;b&gt;</span></p>"},
+			{source: "- x <i>y</i>", expected: "<ul><li>x <i>y</i></li></ul>"},
+			{source: "a <script>alert(1)</script> b", expected: "<p>a  b</p>"},
+			{source: "- [ ] todo <i>raw</i>", expected: `<ul><li><input type="checkbox" disabled />todo <i>raw</i></li></ul>`},
+			{
+				source: "x[^1] <b>y</b>\n\n[^1]: note <i>z</i>",
+				expected: `<p>x<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup> <b>y</b></p>` +
+					`<div class="footnotes" role="doc-endnotes"><hr /><ol><li id="fn:1"><p>note <i>z</i>` + "\u00a0" +
+					`<a href="#fnref:1" class="footnote-backref" role="doc-backlink">↩︎</a></p></li></ol></div>`,
+			},
+		}
+
+		r := New()
+		r.Policy = mx.UGCPolicy()
+		for _, tc := range testCases {
+			b := &strings.Builder{}
+			r.Render([]byte(tc.source))(&mx.Node{Writer: b})
+			assert.Equal(t, tc.expected, b.String(), tc.source)
+		}
+	})
+
+	t.Run("custom renderers next to inline HTML", func(t *testing.T) {
+		r := New()
+		r.Policy = mx.UGCPolicy()
+		r.Renderers[ast.KindLink] = func(n *mx.Node, node ast.Node, source []byte, children func(*mx.Node)) {
+			href := string(node.(*ast.Link).Destination)
+			n.A(mx.Slice{mx.M{"href": href}, mx.S(`target="_blank" data-track="link"`)}, children)
+		}
+		b := &strings.Builder{}
+		r.Render([]byte("[a](/x) <b onclick=\"x\">raw</b>"))(&mx.Node{Writer: b})
+		assert.Equal(t, `<p><a href="/x" target="_blank" data-track="link">a</a> <b>raw</b></p>`, b.String())
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		source := []byte("# Hello *big* `world` &amp; co")
+		doc := New().parser.Parse(text.NewReader(source))
+		assert.Equal(t, "Hello big world & co", PlainText(doc.FirstChild(), source))
+	})
+}
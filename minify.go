@@ -0,0 +1,152 @@
+package mx
+
+import (
+	"strings"
+)
+
+// Elements that close an open <p> when they start
+var closesP = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"details": true, "dialog": true, "div": true, "dl": true, "fieldset": true,
+	"figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hgroup": true, "hr": true, "main": true, "menu": true,
+	"nav": true, "ol": true, "p": true, "pre": true, "search": true,
+	"section": true, "table": true, "ul": true,
+}
+
+// Elements whose </p> can't be omitted at their end
+var keepsPEnd = map[string]bool{
+	"a": true, "audio": true, "del": true, "ins": true, "map": true,
+	"noscript": true, "video": true,
+}
+
+// Elements whose whitespace is significant
+var preserveSpaceTags = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+	"script":   true,
+	"style":    true,
+}
+
+// Characters that require an attribute value to be quoted
+const quotedAttrChars = " \t\n\r\f\"'=<>`"
+
+// minifying checks if minified output is enabled. DevMode takes precedence.
+func (n *Node) minifying() bool {
+	return n.Minify && !n.DevMode && n.Backend == nil
+}
+
+// flushEndTag writes the end tag held back by Minify, unless the HTML spec
+// allows omitting it before next. next is the tag about to be opened, "" for
+// text and "/" for the end of the parent element.
+func (n *Node) flushEndTag(next string) {
+	if n.pending == "" {
+		return
+	}
+	tag := n.pending
+	n.pending = ""
+	if !canOmitEndTag(tag, next, n.parent()) {
+		n.write("</" + tag + ">")
+	}
+}
+
+// parent returns the innermost open element, or "" at the top level.
+func (n *Node) parent() string {
+	if len(n.stack) == 0 {
+		return ""
+	}
+	return n.stack[len(n.stack)-1].tag
+}
+
+// preservesSpace checks if the text being written is inside an element whose
+// whitespace is significant.
+func (n *Node) preservesSpace() bool {
+	for _, f := range n.stack {
+		if preserveSpaceTags[f.tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOptionalEndTag checks if tag's end tag may be omitted in some contexts.
+func hasOptionalEndTag(tag string) bool {
+	switch tag {
+	case "li", "p", "td", "th", "tr", "option", "dt", "dd", "thead", "tbody", "tfoot":
+		return true
+	}
+	return false
+}
+
+// canOmitEndTag implements the optional end tag rules of the HTML spec.
+func canOmitEndTag(tag, next, parent string) bool {
+	end := next == "/"
+	switch tag {
+	case "li":
+		return end || next == "li"
+	case "p":
+		return (end && !keepsPEnd[parent]) || closesP[next]
+	case "td", "th":
+		return end || next == "td" || next == "th"
+	case "tr":
+		return end || next == "tr"
+	case "option":
+		return end || next == "option" || next == "optgroup"
+	case "dt":
+		return next == "dt" || next == "dd"
+	case "dd":
+		return end || next == "dd" || next == "dt"
+	case "thead":
+		return next == "tbody" || next == "tfoot"
+	case "tbody":
+		return end || next == "tbody" || next == "tfoot"
+	case "tfoot":
+		return end
+	}
+	return false
+}
+
+// minifyAttrs rewrites a rendered attribute string with the shortest safe
+// quoting: empty values are dropped, which is equivalent to a bare attribute,
+// and values are unquoted when they can be.
+func minifyAttrs(s string) string {
+	var b strings.Builder
+	for i, a := range parseAttrs(s) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.name)
+		switch {
+		case !a.hasValue || a.value == "":
+		case !strings.ContainsAny(a.value, quotedAttrChars):
+			b.WriteString("=" + a.value)
+		case a.quote == '\'':
+			b.WriteString(`='` + a.value + `'`)
+		default:
+			b.WriteString(`="` + a.value + `"`)
+		}
+	}
+	return b.String()
+}
+
+// collapseSpace replaces each run of whitespace in s with a single space.
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(s); i++ {
+		if isSpace(s[i]) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteByte(s[i])
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+type (
+	minifyTestCase struct {
+		component func(*Node)
+		expected  string
+	}
+)
+
+func TestMinify(t *testing.T) {
+	testCases := []minifyTestCase{
+		{
+			expected: `<ul><li>a<li>b</ul>`,
+			component: func(n *Node) {
+				n.Ul(nil, func(n *Node) {
+					n.Li(nil, Text("a"))
+					n.Li(nil, Text("b"))
+				})
+			},
+		},
+		{
+			expected: `<div><p>a<p>b<div></div></div>`,
+			component: func(n *Node) {
+				n.Div(nil, func(n *Node) {
+					n.P(nil, Text("a"))
+					n.P(nil, Text("b"))
+					n.Div(nil)
+				})
+			},
+		},
+		{
+			expected: `<a href=/x><p>a</p></a><p>b</p>text`,
+			component: func(n *Node) {
+				n.A(M{"href": "/x"}, func(n *Node) {
+					n.P(nil, Text("a"))
+				})
+				n.P(nil, Text("b"))
+				Text("text")(n)
+			},
+		},
+		{
+			expected: `<p>a</p><span>b</span>`,
+			component: func(n *Node) {
+				n.P(nil, Text("a"))
+				n.Span(nil, Text("b"))
+			},
+		},
+		{
+			expected: `<table><thead><tr><th>a<th>b<tbody><tr><td>1<td>2<tr><td>3<td>4</table>`,
+			component: func(n *Node) {
+				n.Table(nil, func(n *Node) {
+					n.THead(nil, func(n *Node) {
+						n.Tr(nil, func(n *Node) {
+							n.Th(nil, Text("a"))
+							n.Th(nil, Text("b"))
+						})
+					})
+					n.TBody(nil, func(n *Node) {
+						n.Tr(nil, func(n *Node) {
+							n.Td(nil, Text("1"))
+							n.Td(nil, Text("2"))
+						})
+						n.Tr(nil, func(n *Node) {
+							n.Td(nil, Text("3"))
+							n.Td(nil, Text("4"))
+						})
+					})
+				})
+			},
+		},
+		{
+			expected: `<dl><dt>a<dd>b</dl>`,
+			component: func(n *Node) {
+				n.Dl(nil, func(n *Node) {
+					n.Dt(nil, Text("a"))
+					n.Dd(nil, Text("b"))
+				})
+			},
+		},
+		{
+			expected: `<input type=text class="a b" value='say "hi"' disabled><br>`,
+			component: func(n *Node) {
+				n.Input(S(`type="text" class="a b" value='say "hi"' disabled=""`))
+				n.Br(nil)
+			},
+		},
+		{
+			expected: `<p> a b <pre>  a
+  b</pre>`,
+			component: func(n *Node) {
+				n.P(nil, Text("  a \n\t b  "))
+				n.Pre(nil, Text("  a\n  b"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("expects: %v", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			n := &Node{Writer: b, Minify: true}
+			tc.component(n)
+			assert.Equal(t, tc.expected, b.String())
+			assertSameDOM(t, tc.component, n)
+		})
+	}
+
+	t.Run("dev mode takes precedence", func(t *testing.T) {
+		b := &strings.Builder{}
+		(&Node{Writer: b, Minify: true, DevMode: true}).Hr(nil)
+		assert.Equal(t, "<hr />\n", b.String())
+	})
+}
+
+// assertSameDOM checks if the minified output parses to the same DOM as the
+// regular output, ignoring collapsed whitespace in text nodes.
+func assertSameDOM(t *testing.T, component func(*Node), minified *Node) {
+	t.Helper()
+	regular := &strings.Builder{}
+	component(&Node{Writer: regular})
+	assert.Equal(t, parseDOM(t, regular.String()), parseDOM(t, minified.Writer.(*strings.Builder).String()))
+}
+
+// parseDOM parses s as a document and renders it back in a normalized form.
+func parseDOM(t *testing.T, s string) string {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(s))
+	assert.NoError(t, err)
+	var walk func(*html.Node, bool)
+	walk = func(node *html.Node, pre bool) {
+		if node.Type == html.TextNode && !pre {
+			node.Data = collapseSpace(node.Data)
+		}
+		pre = pre || node.Type == html.ElementNode && preserveSpaceTags[node.Data]
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, pre)
+		}
+	}
+	walk(doc, false)
+	b := &strings.Builder{}
+	assert.NoError(t, html.Render(b, doc))
+	return b.String()
+}
@@ -0,0 +1,251 @@
+// Package mxtest renders mx components in tests and queries the output with
+// CSS selectors, so assertions target elements instead of substrings:
+//
+//	doc := mxtest.Render(t, SignupForm)
+//	doc.Find("form input[name=email]").AssertAttr("type", "email")
+//	doc.Find("button").AssertText("Sign up")
+//
+// Failed assertions report the selector, a diff of the values, and the
+// rendered HTML.
+package mxtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// Doc is the parsed output of a component.
+	Doc struct {
+		HTML string // the rendered output
+		t    testing.TB
+		root *html.Node
+	}
+
+	// Selection is the elements matched by a selector, in document order.
+	Selection struct {
+		Nodes    []*html.Node
+		selector string
+		doc      *Doc
+	}
+)
+
+// Render renders a component and parses its output. Render errors fail the
+// test.
+func Render(t testing.TB, component func(*mx.Node)) *Doc {
+	t.Helper()
+	return RenderNode(t, &mx.Node{}, component)
+}
+
+// RenderNode renders a component with the settings of n, e.g. DevMode, and
+// parses its output. n.Writer is replaced.
+func RenderNode(t testing.TB, n *mx.Node, component func(*mx.Node)) *Doc {
+	t.Helper()
+	b := &strings.Builder{}
+	n.Writer = b
+	component(n)
+	if err := mx.Error(n); err != nil {
+		t.Errorf("mxtest: render failed: %v", err)
+	}
+	return Parse(t, b.String())
+}
+
+// Parse parses HTML. Output that starts with a doctype or <html> is parsed as
+// a document, and anything else as the content of <body>.
+func Parse(t testing.TB, s string) *Doc {
+	t.Helper()
+	d := &Doc{HTML: s, t: t}
+	head := strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(head, "<!doctype") || strings.HasPrefix(head, "<html") {
+		root, err := html.Parse(strings.NewReader(s))
+		if err != nil {
+			t.Fatalf("mxtest: %v", err)
+		}
+		d.root = root
+		return d
+	}
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), body)
+	if err != nil {
+		t.Fatalf("mxtest: %v", err)
+	}
+	d.root = &html.Node{Type: html.DocumentNode}
+	for _, node := range nodes {
+		d.root.AppendChild(node)
+	}
+	return d
+}
+
+// Find returns the elements matching a CSS selector. An invalid selector
+// fails the test.
+func (d *Doc) Find(selector string) *Selection {
+	d.t.Helper()
+	return d.find(selector, selector, nil)
+}
+
+// Find returns the descendants of the selected elements matching a CSS
+// selector.
+func (s *Selection) Find(selector string) *Selection {
+	s.doc.t.Helper()
+	return s.doc.find(selector, s.selector+" "+selector, append([]*html.Node{}, s.Nodes...))
+}
+
+// Len returns the number of selected elements.
+func (s *Selection) Len() int {
+	return len(s.Nodes)
+}
+
+// Text returns the text of the selected elements, with whitespace collapsed
+// as a browser would display it.
+func (s *Selection) Text() string {
+	b := &strings.Builder{}
+	for _, node := range s.Nodes {
+		text(b, node)
+		b.WriteByte(' ')
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Attr returns an attribute of the first selected element, and whether it is
+// present.
+func (s *Selection) Attr(name string) (string, bool) {
+	if len(s.Nodes) == 0 {
+		return "", false
+	}
+	for _, a := range s.Nodes[0].Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// HTML returns the outer HTML of the selected elements.
+func (s *Selection) HTML() string {
+	b := &strings.Builder{}
+	for _, node := range s.Nodes {
+		_ = html.Render(b, node)
+	}
+	return b.String()
+}
+
+// AssertExists asserts that the selector matches at least one element.
+func (s *Selection) AssertExists() bool {
+	s.doc.t.Helper()
+	if len(s.Nodes) == 0 {
+		return assert.Fail(s.doc.t, "no element matches "+s.selector, s.context())
+	}
+	return true
+}
+
+// AssertNotExists asserts that the selector matches no elements.
+func (s *Selection) AssertNotExists() bool {
+	s.doc.t.Helper()
+	if len(s.Nodes) > 0 {
+		return assert.Fail(s.doc.t, "unexpected elements match "+s.selector, "%s\n%s", s.HTML(), s.context())
+	}
+	return true
+}
+
+// AssertCount asserts that the selector matches exactly expected elements.
+func (s *Selection) AssertCount(expected int) bool {
+	s.doc.t.Helper()
+	return assert.Equal(s.doc.t, expected, len(s.Nodes), "count of %s\n%s", s.selector, s.context())
+}
+
+// AssertText asserts the text of the selected elements, as returned by Text.
+func (s *Selection) AssertText(expected string) bool {
+	s.doc.t.Helper()
+	if !s.AssertExists() {
+		return false
+	}
+	return assert.Equal(s.doc.t, expected, s.Text(), "text of %s\n%s", s.selector, s.context())
+}
+
+// AssertContainsText asserts that the text of the selected elements contains
+// expected.
+func (s *Selection) AssertContainsText(expected string) bool {
+	s.doc.t.Helper()
+	if !s.AssertExists() {
+		return false
+	}
+	return assert.Contains(s.doc.t, s.Text(), expected, "text of %s\n%s", s.selector, s.context())
+}
+
+// AssertAttr asserts the value of an attribute of the first selected element.
+func (s *Selection) AssertAttr(name, expected string) bool {
+	s.doc.t.Helper()
+	if !s.AssertExists() {
+		return false
+	}
+	value, ok := s.Attr(name)
+	if !ok {
+		return assert.Fail(s.doc.t, "missing attribute "+name+" on "+s.selector, "%s\n%s", s.HTML(), s.context())
+	}
+	return assert.Equal(s.doc.t, expected, value, "attribute %s of %s\n%s", name, s.selector, s.context())
+}
+
+// find returns the elements matching a selector, in document order. With a
+// scope, only descendants of the scope elements are returned. name describes
+// the selection in failure messages.
+func (d *Doc) find(selector, name string, scope []*html.Node) *Selection {
+	d.t.Helper()
+	sel, err := cascadia.Compile(selector)
+	if err != nil {
+		d.t.Fatalf("mxtest: invalid selector %q: %v", selector, err)
+	}
+	s := &Selection{selector: name, doc: d}
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if sel.Match(node) && (scope == nil || hasAncestor(node, scope)) {
+			s.Nodes = append(s.Nodes, node)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(d.root)
+	return s
+}
+
+// hasAncestor checks if any of nodes is an ancestor of node.
+func hasAncestor(node *html.Node, nodes []*html.Node) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if slices.Contains(nodes, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// context describes the document for failure messages.
+func (s *Selection) context() string {
+	return "in:\n" + s.doc.HTML
+}
+
+// text writes the text content of a node.
+func text(b *strings.Builder, node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		b.WriteString(node.Data)
+	case html.ElementNode:
+		if node.Data == "script" || node.Data == "style" {
+			return
+		}
+		if node.Data == "br" {
+			b.WriteByte(' ')
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text(b, child)
+	}
+}
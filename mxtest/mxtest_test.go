@@ -0,0 +1,149 @@
+package mxtest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// recorder records the failures of a test instead of failing it.
+	recorder struct {
+		testing.TB
+		errors []string
+		fatal  bool
+	}
+
+	findTestCase struct {
+		selector string
+		expected []string
+	}
+)
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+	runtime.Goexit()
+}
+
+// run runs f with a recorder, in a goroutine so Fatalf can stop it.
+func run(t *testing.T, f func(t testing.TB)) *recorder {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(r)
+	}()
+	<-done
+	return r
+}
+
+func signupForm(n *mx.Node) {
+	n.Form(mx.M{"action": "/signup", "method": "post"}, func(n *mx.Node) {
+		n.Label(nil, mx.Text("Email "), func(n *mx.Node) {
+			n.Input(mx.M{"type": "email", "name": "email"})
+		})
+		n.Input(mx.M{"type": "password", "name": "password"})
+		n.Button(nil, mx.Text("Sign  up"))
+	})
+	n.P(mx.S(`class="hint"`), mx.Text("Already have an account? "), func(n *mx.Node) {
+		n.A(mx.M{"href": "/login"}, mx.Text("Log in"))
+	})
+}
+
+func TestFind(t *testing.T) {
+	doc := Render(t, signupForm)
+	testCases := []findTestCase{
+		{selector: "form input", expected: []string{"email", "password"}},
+		{selector: "form input[name=email]", expected: []string{"email"}},
+		{selector: "label > input", expected: []string{"email"}},
+		{selector: "p.hint a, button", expected: []string{"Sign up", "/login"}},
+		{selector: "table", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("finds '%v'", tc.selector)
+		t.Run(name, func(t *testing.T) {
+			found := []string{}
+			for _, node := range doc.Find(tc.selector).Nodes {
+				s := &Selection{Nodes: []*html.Node{node}}
+				if v, ok := s.Attr("name"); ok {
+					found = append(found, v)
+				} else if v, ok := s.Attr("href"); ok {
+					found = append(found, v)
+				} else {
+					found = append(found, s.Text())
+				}
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+
+	t.Run("finds descendants of a selection", func(t *testing.T) {
+		assert.Equal(t, 1, doc.Find("label").Find("input").Len())
+		assert.Equal(t, 0, doc.Find("p").Find("input").Len())
+	})
+
+	t.Run("parses documents", func(t *testing.T) {
+		doc := Parse(t, "<!DOCTYPE html><html><head><title>T</title></head><body><p>x</p></body></html>")
+		doc.Find("head > title").AssertText("T")
+		doc.Find("html > body > p").AssertCount(1)
+	})
+}
+
+func TestAssertions(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		doc := Render(t, signupForm)
+		doc.Find("form").AssertExists()
+		doc.Find("table").AssertNotExists()
+		doc.Find("input").AssertCount(2)
+		doc.Find("form").AssertAttr("method", "post")
+		doc.Find("button").AssertText("Sign up")
+		doc.Find("p").AssertText("Already have an account? Log in")
+		doc.Find("p").AssertContainsText("account")
+	})
+
+	t.Run("fail with the selector, a diff and the HTML", func(t *testing.T) {
+		r := run(t, func(t testing.TB) {
+			Render(t, signupForm).Find("form button").AssertText("Sign in")
+		})
+		if assert.Len(t, r.errors, 1) {
+			assert.Contains(t, r.errors[0], "text of form button")
+			assert.Contains(t, r.errors[0], "-Sign in\n")
+			assert.Contains(t, r.errors[0], "+Sign up\n")
+			assert.Contains(t, r.errors[0], `<button>Sign  up</button></form>`)
+		}
+	})
+
+	t.Run("fail on missing elements and attributes", func(t *testing.T) {
+		r := run(t, func(t testing.TB) {
+			doc := Render(t, signupForm)
+			doc.Find("textarea").AssertText("x")
+			doc.Find("button").AssertAttr("type", "submit")
+		})
+		if assert.Len(t, r.errors, 2) {
+			assert.Contains(t, r.errors[0], "no element matches textarea")
+			assert.Contains(t, r.errors[1], "missing attribute type on button")
+		}
+	})
+
+	t.Run("fail on invalid selectors", func(t *testing.T) {
+		r := run(t, func(t testing.TB) {
+			Render(t, signupForm).Find("form[")
+		})
+		assert.True(t, r.fatal)
+		assert.Contains(t, strings.Join(r.errors, ""), `invalid selector "form["`)
+	})
+}
@@ -0,0 +1,122 @@
+package mxtest
+
+import (
+	"flag"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	nethtml "golang.org/x/net/html"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+// The flag is namespaced so it can't clash with an -update flag defined by the
+// tests, which doesn't write golden files.
+var update = flag.Bool("mxtest.update", false, "rewrite the golden files of mxtest.Snapshot")
+
+// Elements whose content is kept as written in snapshots
+var rawTextTags = map[string]bool{"pre": true, "textarea": true, "script": true, "style": true}
+
+// Snapshot renders a component and compares its normalized output with
+// testdata/<name>.golden.html. Run the tests with -mxtest.update to write the
+// golden files.
+//
+// In the normalized form each element and text node is on its own line, text
+// with only text inside is on its element's line, attributes are sorted, and
+// whitespace in text is collapsed, so golden files are stable and easy to
+// review.
+func Snapshot(t testing.TB, name string, component func(*mx.Node)) bool {
+	t.Helper()
+	actual := Render(t, component).Normalize()
+	path := filepath.Join("testdata", name+".golden.html")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("mxtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("mxtest: %v", err)
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return assert.Fail(t, "missing golden file "+path, "run the tests with -mxtest.update to create it")
+	} else if err != nil {
+		t.Fatalf("mxtest: %v", err)
+	}
+	return assert.Equal(t, string(expected), actual, "snapshot %s differs, run the tests with -mxtest.update to accept it", path)
+}
+
+// Normalize returns the normalized form of the document used by Snapshot.
+func (d *Doc) Normalize() string {
+	b := &strings.Builder{}
+	for child := d.root.FirstChild; child != nil; child = child.NextSibling {
+		normalize(b, child, 0)
+	}
+	return b.String()
+}
+
+// normalize writes the normalized form of a node.
+func normalize(b *strings.Builder, node *nethtml.Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch node.Type {
+	case nethtml.DoctypeNode:
+		b.WriteString("<!DOCTYPE " + node.Data + ">\n")
+	case nethtml.CommentNode:
+		b.WriteString(indent + "<!--" + node.Data + "-->\n")
+	case nethtml.TextNode:
+		if text := strings.Join(strings.Fields(node.Data), " "); text != "" {
+			b.WriteString(indent + html.EscapeString(text) + "\n")
+		}
+	case nethtml.ElementNode:
+		b.WriteString(indent + "<" + node.Data)
+		attrs := slices.Clone(node.Attr)
+		slices.SortFunc(attrs, func(a, b nethtml.Attribute) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+		for _, a := range attrs {
+			b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+		}
+		b.WriteString(">")
+
+		switch {
+		case node.FirstChild == nil && isVoid(node.Data):
+		case rawTextTags[node.Data]:
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				_ = nethtml.Render(b, child)
+			}
+			b.WriteString("</" + node.Data + ">")
+		case node.FirstChild == nil || node.FirstChild == node.LastChild && node.FirstChild.Type == nethtml.TextNode:
+			if node.FirstChild != nil {
+				b.WriteString(html.EscapeString(strings.Join(strings.Fields(node.FirstChild.Data), " ")))
+			}
+			b.WriteString("</" + node.Data + ">")
+		default:
+			b.WriteString("\n")
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				normalize(b, child, depth+1)
+			}
+			b.WriteString(indent + "</" + node.Data + ">")
+		}
+		b.WriteString("\n")
+	default:
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			normalize(b, child, depth)
+		}
+	}
+}
+
+// isVoid checks if an element has no end tag.
+func isVoid(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
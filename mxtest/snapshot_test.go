@@ -0,0 +1,101 @@
+package mxtest
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+func TestNormalize(t *testing.T) {
+	doc := Parse(t, `<!DOCTYPE html><html lang="en"><body class="x" id="top">`+
+		`<p>Hello,   <b>world</b>!</p><pre>  a
+  b</pre><br><ul><li>one</li><li></li></ul></body></html>`)
+	assert.Equal(t, `<!DOCTYPE html>
+<html lang="en">
+  <head></head>
+  <body class="x" id="top">
+    <p>
+      Hello,
+      <b>world</b>
+      !
+    </p>
+    <pre>  a
+  b</pre>
+    <br>
+    <ul>
+      <li>one</li>
+      <li></li>
+    </ul>
+  </body>
+</html>
+`, doc.Normalize())
+}
+
+// An -update flag of the tests, which must not clash with mxtest's
+var appUpdate = flag.Bool("update", false, "update the golden files of the app")
+
+func TestSnapshot(t *testing.T) {
+	// The failures are checked against the golden files, even with -mxtest.update
+	updating, appUpdating := *update, *appUpdate
+	defer func() { *update, *appUpdate = updating, appUpdating }()
+
+	t.Run("matches the golden file", func(t *testing.T) {
+		Snapshot(t, "signup_form", signupForm)
+	})
+
+	t.Run("is stable with M attributes", func(t *testing.T) {
+		for range 20 {
+			Snapshot(t, "signup_form", signupForm)
+		}
+	})
+
+	t.Run("fails on differences", func(t *testing.T) {
+		*update = false
+		r := run(t, func(t testing.TB) {
+			Snapshot(t, "signup_form", func(n *mx.Node) {
+				n.Form(mx.M{"action": "/signup", "method": "get"}, nil)
+			})
+		})
+		if assert.Len(t, r.errors, 1) {
+			assert.Contains(t, r.errors[0], `-<form action="/signup" method="post">`)
+			assert.Contains(t, r.errors[0], `+<form action="/signup" method="get"></form>`)
+			assert.Contains(t, r.errors[0], "run the tests with -mxtest.update")
+		}
+	})
+
+	t.Run("fails on missing golden files", func(t *testing.T) {
+		*update = false
+		r := run(t, func(t testing.TB) {
+			Snapshot(t, "missing", signupForm)
+		})
+		if assert.Len(t, r.errors, 1) {
+			assert.Contains(t, r.errors[0], "missing golden file testdata/missing.golden.html")
+		}
+	})
+
+	t.Run("writes golden files with -mxtest.update", func(t *testing.T) {
+		*update = true
+		t.Chdir(t.TempDir())
+
+		Snapshot(t, "signup_form", signupForm)
+		b, err := os.ReadFile("testdata/signup_form.golden.html")
+		assert.NoError(t, err)
+		assert.Contains(t, string(b), "<button>Sign up</button>\n")
+	})
+
+	t.Run("ignores the tests' -update", func(t *testing.T) {
+		*update, *appUpdate = false, true
+		t.Chdir(t.TempDir())
+
+		r := run(t, func(t testing.TB) {
+			Snapshot(t, "signup_form", signupForm)
+		})
+		assert.Len(t, r.errors, 1)
+		_, err := os.ReadFile("testdata/signup_form.golden.html")
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,16 @@
+// Command mxvet is a go vet-style checker for code using mx. It reports
+// non-constant strings converted to mx.HTML.
+//
+//	go run ./mxvet ./...
+//	go vet -vettool=$(which mxvet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/jlucasnsilva/mx/mxvet/rawcheck"
+)
+
+func main() {
+	singlechecker.Main(rawcheck.Analyzer)
+}
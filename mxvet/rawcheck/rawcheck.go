@@ -0,0 +1,68 @@
+// Package rawcheck defines an analyzer that reports non-constant strings
+// converted to mx.HTML.
+package rawcheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const mxPath = "github.com/jlucasnsilva/mx"
+
+// Analyzer reports conversions of non-constant strings to mx.HTML anywhere in a
+// package, e.g. mx.HTML("<b>" + name + "</b>"), whether the result is passed to
+// mx.Raw right away or through a variable or a field. Values that already have
+// type mx.HTML or html/template.HTML are trusted, and so is the mx package
+// itself, where the sanitizer builds its output. Output written to Node.Writer
+// or a Backend directly isn't checked, so packages built on mx must go through
+// Raw, or through mx.Sanitize and mx.SanitizeParts for untrusted HTML.
+var Analyzer = &analysis.Analyzer{
+	Name:     "rawcheck",
+	Doc:      "report non-constant strings converted to mx.HTML",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Path() == mxPath {
+		return nil, nil
+	}
+	in := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	in.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(node ast.Node) {
+		if isUnsafeConversion(pass.TypesInfo, node.(*ast.CallExpr)) {
+			pass.Reportf(node.Pos(), "non-constant string converted to mx.HTML; escape it with mx.Text or sanitize it")
+		}
+	})
+	return nil, nil
+}
+
+// isUnsafeConversion checks if conv is a conversion to mx.HTML whose operand is
+// a non-constant string.
+func isUnsafeConversion(info *types.Info, conv *ast.CallExpr) bool {
+	if len(conv.Args) != 1 {
+		return false
+	}
+	tv, ok := info.Types[conv.Fun]
+	if !ok || !tv.IsType() || !isNamed(tv.Type, mxPath, "HTML") {
+		return false
+	}
+	arg := info.Types[conv.Args[0]]
+	if arg.Value != nil {
+		return false
+	}
+	return !isNamed(arg.Type, mxPath, "HTML") && !isNamed(arg.Type, "html/template", "HTML")
+}
+
+// isNamed checks if t is the named type pkg.name.
+func isNamed(t types.Type, pkg, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkg && obj.Name() == name
+}
@@ -0,0 +1,27 @@
+package example
+
+import (
+	"html/template"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+const banner = "<b>hi</b>"
+
+func components(name string, trusted mx.HTML, tmpl template.HTML) {
+	mx.Raw("<!DOCTYPE html>")
+	mx.Raw(banner)
+	mx.Raw(mx.HTML("<hr>" + banner))
+	mx.Raw(trusted)
+	mx.Raw(mx.HTML(trusted))
+	mx.Raw(mx.HTML(tmpl))
+	mx.Raw(mx.HTML(name))                    // want `non-constant string converted to mx.HTML`
+	mx.Raw((mx.HTML("<b>" + name + "</b>"))) // want `non-constant string converted to mx.HTML`
+
+	html := mx.HTML("<i>" + name + "</i>") // want `non-constant string converted to mx.HTML`
+	mx.Raw(html)
+	page := struct{ Body mx.HTML }{Body: mx.HTML(name)} // want `non-constant string converted to mx.HTML`
+	mx.Raw(page.Body)
+	var constant mx.HTML = "<br>"
+	mx.Raw(constant)
+}
@@ -4,76 +4,126 @@
 package mx
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"io"
+	"runtime/metrics"
 	"strings"
+	"time"
 )
 
+// HTML is a string of markup trusted to be safe. Keeping it a distinct type makes
+// every place where unescaped HTML enters a page an explicit conversion, e.g.
+// HTML(someTemplateHTML), or the result of a sanitizer.
+type HTML string
+
 // Node represents an HTML node being rendered.
 type Node struct {
-	Writer  io.Writer         // where HTML output is written to (usually http.ResponseWriter)
-	err     error             // stores the first write error encountered during rendering
-	indent  int               // used for pretty printing indentation in dev mode
-	DevMode bool              // enables pretty printing and dev features like data-node
-	writeFn func(func(*Node)) // optional hook to intercept element rendering (used by WrapEach)
+	Writer   io.Writer         // where HTML output is written to (usually http.ResponseWriter)
+	Backend  Backend           // receives the output as events instead of Writer; DevMode formatting and Minify only apply to Writer
+	err      error             // stores the first write error encountered during rendering
+	indent   int               // used for pretty printing indentation in dev mode
+	flat     int               // > 0 while writing content that dev mode must not reformat
+	midLine  bool              // whether dev mode has written content on the current line
+	breaks   int               // line breaks written by dev mode
+	DevMode  bool              // enables pretty printing and dev features like component tracing
+	Trace    bool              // in dev mode, annotates component roots with data-mx-component and data-mx-source
+	Markers  bool              // with Trace, also marks component boundaries with HTML comments
+	Minify   bool              // omits optional end tags and quotes, and collapses whitespace (ignored in dev mode)
+	Validate bool              // in dev mode, reports elements that break the HTML content models
+	A11y     bool              // in dev mode, reports accessibility issues
+	Target   string            // renders only the element with this id, or the Part with this name
+	pending  string            // end tag held back by Minify until the next write
+	writeFn  func(func(*Node)) // optional hook to intercept element rendering (set by WrapEach)
+	stack    []frame           // the open elements
+
+	Interceptors []Interceptor // change how elements are rendered, in order
+	intercepted  int           // > 0 while interceptors render, so their output isn't intercepted
+	bypass       bool          // the next element was already intercepted
+
+	Profiler *Profiler        // records time, bytes and allocations per element subtree when set
+	spans    []span           // elements being profiled
+	allocs   []metrics.Sample // reads the heap allocations for the profiler
+	Observer StatsObserver    // receives the render stats at the end of Render
+	stats    RenderStats      // what has been rendered so far
+	started  time.Time        // time of the first write
+
+	violations []*Violation      // content model violations found by Validate
+	ids        map[string]string // file:line where each id was first used, for Validate
+	issues     []*A11yIssue      // accessibility issues found by A11y
+	labels     map[string]bool   // ids referenced by <label for>, for A11y
+	heading    int               // level of the last heading, for A11y
+
+	selection    selection // progress through the page when Target is set
+	selectIndent int       // indentation of the selected subtree in the page
 }
 
 // Text writes escaped text.
 func Text(text string) func(*Node) {
 	return func(n *Node) {
-		n.writeIndent()
-		n.write(html.EscapeString(text))
-
-		if n.DevMode {
-			n.write("\n")
-		}
+		n.startInline()
+		n.writeText(text)
 	}
 }
 
 // Textf writes formatted escaped text.
 func Textf(format string, args ...any) func(*Node) {
 	return func(n *Node) {
-		n.writeIndent()
-		t := fmt.Sprintf(format, args...)
-		n.write(html.EscapeString(t))
-
-		if n.DevMode {
-			n.write("\n")
-		}
+		n.startInline()
+		n.writeText(fmt.Sprintf(format, args...))
 	}
 }
 
-// Raw writes unescaped HTML. Use with caution.
-func Raw(raw string) func(*Node) {
+// Raw writes unescaped HTML. Use with caution: run mxvet to find calls whose
+// argument is converted from a non-constant string.
+func Raw(raw HTML) func(*Node) {
 	return func(n *Node) {
-		n.writeIndent()
-		n.write(raw)
-
-		if n.DevMode {
-			n.write("\n")
+		n.startInline()
+		n.flushEndTag("")
+		if n.linting() && raw != "" {
+			n.name()
 		}
+		if n.emitting() && raw != "" {
+			n.err = n.Backend.Raw(raw)
+		}
+		n.write(string(raw))
 	}
 }
 
-// Error returns the write error if any occurred during rendering.
+// Error returns the write error if any occurred during rendering, joined with
+// the content model violations found by Validate that aren't warnings, and
+// ErrTargetNotFound if Target matched nothing.
 func Error(n *Node) error {
-	return n.err
+	errs := []error{n.err}
+	if n.Target != "" && n.selection == selectBefore {
+		errs = append(errs, ErrTargetNotFound)
+	}
+	for _, v := range n.violations {
+		if !v.Warning {
+			errs = append(errs, v)
+		}
+	}
+	if len(errs) == 1 {
+		return n.err
+	}
+	return errors.Join(errs...)
 }
 
 // WrapEach intercepts a function that renders multiple sibling elements and wraps each one
-// with a provided wrapper (e.g., a div with a class).
+// with a provided wrapper (e.g., a div with a class). The children render on n
+// itself, so they share its output and state, e.g. Target, Minify and Interceptors.
 func WrapEach(n *Node, wrapper func(*Node, func(*Node)), children func(*Node)) {
-	proxy := &Node{
-		Writer:  n.Writer,
-		DevMode: n.DevMode,
-		indent:  n.indent,
-		err:     n.err,
-		writeFn: func(inner func(*Node)) {
-			wrapper(n, inner)
-		},
-	}
-	children(proxy)
+	outer := n.writeFn
+	var wrap func(func(*Node))
+	wrap = func(inner func(*Node)) {
+		n.writeFn = outer
+		defer func() { n.writeFn = wrap }()
+		wrapper(n, inner)
+	}
+	n.writeFn = wrap
+	defer func() { n.writeFn = outer }()
+	children(n)
 }
 
 // el renders an HTML element with tag, attributes, and children.
@@ -84,50 +134,154 @@ func (n *Node) el(tag string, attr Attr, children ...func(*Node)) {
 		})
 		return
 	}
+	if n.skipping() {
+		return
+	}
+	if n.intercepting() {
+		n.intercept(tag, attr, children)
+		return
+	}
 
-	n.writeIndent()
-	n.write("<" + tag)
+	f := frame{tag: tag}
+	var source string
+	if n.tracing() || n.Profiler != nil || n.validating() || n.linting() {
+		f.component, source = caller()
+	}
+	root := f.component != "" && f.component != n.component()
+	n.countElement()
+	if n.Profiler != nil {
+		n.startSpan(f, root)
+		defer n.endSpan()
+	}
+
+	block := n.isBlock(tag)
+	if block {
+		n.startBlock()
+	} else {
+		n.startInline()
+	}
+	n.flushEndTag(tag)
+	if root && n.tracing() && n.Markers {
+		n.write(traceComment(f.component + " " + source))
+	}
+	var attrs string
 	if attr != nil {
-		attrs := attr.Attributes()
-		if attrs != "" {
-			n.write(" " + attrs)
+		attrs = attr.Attributes()
+	}
+	if n.validating() {
+		n.validate(f, attrs, source)
+	}
+	if n.linting() {
+		n.lint(&f, attrs, source)
+	}
+	if n.selects(attrs) {
+		n.startSelection()
+		defer n.endSelection()
+	}
+	n.write("<" + tag)
+	if n.minifying() {
+		attrs = minifyAttrs(attrs)
+	}
+	if attrs != "" {
+		n.write(" " + attrs)
+	}
+	if root && n.tracing() {
+		n.write(" " + traceAttrs(f.component, source))
+	}
+	if n.emitting() {
+		if root && n.tracing() {
+			attrs = strings.TrimPrefix(attrs+" "+traceAttrs(f.component, source), " ")
 		}
+		n.emitOpen(tag, attr, attrs)
+		defer n.emitClose(tag)
 	}
 
 	if isVoidTag(tag) {
-		n.write(" />")
-		if n.DevMode {
-			n.write("\n")
+		if n.minifying() {
+			n.write(">")
+			return
 		}
+		n.write(" />")
+		n.endElement(f, root, block)
 		return
 	}
 
 	n.write(">")
-	if n.DevMode {
-		n.write("\n")
+	// Blocks have their children indented, unless whitespace is significant in
+	// them, and inline-only content stays on the tag's line. Everything inside
+	// inline elements is written flat, even when they are on their own line as
+	// children of a container.
+	nested := block && blockTags[tag] && !preserveSpaceTags[tag]
+	breaks := n.breaks
+	if nested {
 		n.indent++
+	} else if n.DevMode {
+		n.flat++
 	}
+	n.stack = append(n.stack, f)
 	for _, child := range children {
 		if child != nil {
 			child(n)
 		}
 	}
-	if n.DevMode {
+	n.flushEndTag("/")
+	if n.linting() {
+		n.lintEnd()
+	}
+	n.stack = n.stack[:len(n.stack)-1]
+	if nested {
 		n.indent--
-		n.writeIndent()
+		if n.breaks != breaks {
+			n.startBlock()
+		}
+	} else if n.DevMode {
+		n.flat--
+	}
+	if n.minifying() && hasOptionalEndTag(tag) {
+		n.pending = tag
+		return
 	}
 	n.write("</" + tag + ">")
-	if n.DevMode {
-		n.write("\n")
+	n.endElement(f, root, block)
+}
+
+// endElement finishes the line of a block and the boundary of a component
+// root after an element's end tag.
+func (n *Node) endElement(f frame, root, block bool) {
+	if root && n.tracing() && n.Markers {
+		n.write(traceComment("/" + f.component))
+	}
+	if block {
+		n.endBlock()
 	}
 }
 
 // write safely writes to the writer and sets error if occurred.
 func (n *Node) write(s string) {
-	if n.err != nil {
+	if n.err != nil || n.suppressed() || n.Backend != nil {
 		return
 	}
-	_, n.err = io.WriteString(n.Writer, s)
+	if n.started.IsZero() {
+		n.started = time.Now()
+	}
+	var k int
+	k, n.err = io.WriteString(n.Writer, s)
+	n.stats.Bytes += int64(k)
+}
+
+// writeText writes escaped text, collapsing whitespace when minifying.
+func (n *Node) writeText(text string) {
+	n.flushEndTag("")
+	if n.minifying() && !n.preservesSpace() {
+		text = collapseSpace(text)
+	}
+	if n.linting() && strings.TrimSpace(text) != "" {
+		n.name()
+	}
+	if n.emitting() && text != "" {
+		n.err = n.Backend.Text(text)
+	}
+	n.write(html.EscapeString(text))
 }
 
 // writeIndent writes the indentation spaces.
@@ -34,9 +34,7 @@ func TestNode(t *testing.T) {
 		},
 		{
 			indent: true,
-			expected: `<form>
-  <input type="number" />
-</form>
+			expected: `<form><input type="number" /></form>
 `,
 			component: func(n *Node) {
 				n.Form(nil, func(n *Node) {
@@ -63,15 +61,9 @@ func TestNode(t *testing.T) {
 		{
 			indent: true,
 			expected: `<div class="grid">
-  <div class="grid-item">
-    Hello, world!
-  </div>
-  <div class="grid-item">
-    Hello, world!
-  </div>
-  <div class="grid-item">
-    Hello, world!
-  </div>
+  <div class="grid-item">Hello, world!</div>
+  <div class="grid-item">Hello, world!</div>
+  <div class="grid-item">Hello, world!</div>
 </div>
 `,
 			component: func(n *Node) {
@@ -0,0 +1,58 @@
+package mx
+
+// Elements laid out as blocks by default. Whitespace around and at the edges
+// of a block is not rendered, so dev mode can put them on their own lines.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"body": true, "caption": true, "dd": true, "details": true, "dialog": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "head": true,
+	"header": true, "hgroup": true, "hr": true, "html": true, "legend": true,
+	"li": true, "main": true, "menu": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "search": true, "section": true, "summary": true,
+	"table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "ul": true,
+}
+
+// Elements whose whitespace-only children are not rendered, so every child
+// element can go on its own line, inline or not.
+var containerTags = map[string]bool{
+	"audio": true, "colgroup": true, "datalist": true, "dl": true,
+	"head": true, "html": true, "menu": true, "ol": true, "optgroup": true,
+	"picture": true, "select": true, "table": true, "tbody": true,
+	"tfoot": true, "thead": true, "tr": true, "ul": true, "video": true,
+}
+
+// isBlock checks if tag goes on its own line in dev mode. Everything else is
+// inline and is written flat, on the current line, so no whitespace is added
+// where it would be visible.
+func (n *Node) isBlock(tag string) bool {
+	return n.DevMode && n.flat == 0 && (blockTags[tag] || containerTags[n.parent()])
+}
+
+// startInline starts a line for inline content unless one is in progress.
+func (n *Node) startInline() {
+	if !n.DevMode || n.flat > 0 || n.midLine {
+		return
+	}
+	n.writeIndent()
+	n.midLine = true
+}
+
+// startBlock puts a block on a new line.
+func (n *Node) startBlock() {
+	if n.midLine {
+		n.write("\n")
+		n.breaks++
+	}
+	n.writeIndent()
+	n.midLine = true
+}
+
+// endBlock ends the line of a block.
+func (n *Node) endBlock() {
+	n.write("\n")
+	n.breaks++
+	n.midLine = false
+}
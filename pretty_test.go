@@ -0,0 +1,132 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestPretty(t *testing.T) {
+	testCases := []nodeTestCase{
+		{
+			expected: "<p><a href=\"/\">foo</a>bar</p>\n",
+			component: func(n *Node) {
+				n.P(nil, func(n *Node) {
+					n.A(M{"href": "/"}, Text("foo"))
+					Text("bar")(n)
+				})
+			},
+		},
+		{
+			expected: "<div>foo\n  <p><em>a</em> <strong>b</strong></p>\n  bar\n</div>\n",
+			component: func(n *Node) {
+				n.Div(nil, func(n *Node) {
+					Text("foo")(n)
+					n.P(nil, func(n *Node) {
+						n.Em(nil, Text("a"))
+						Text(" ")(n)
+						n.Strong(nil, Text("b"))
+					})
+					Text("bar")(n)
+				})
+			},
+		},
+		{
+			expected: "<div>\n  <pre>  a\n    <b>b</b>\n</pre>\n  <textarea>x\n y</textarea><code> c  d </code>\n</div>\n",
+			component: func(n *Node) {
+				n.Div(nil, func(n *Node) {
+					n.Pre(nil, Text("  a\n    "), func(n *Node) {
+						n.B(nil, Text("b"))
+					}, Text("\n"))
+					n.TextArea(nil, Text("x\n y"))
+					n.Code(nil, Text(" c  d "))
+				})
+			},
+		},
+		{
+			expected: "<ul>\n  <li>a</li>\n  <li><span>b<div>c</div></span></li>\n</ul>\n",
+			component: func(n *Node) {
+				n.Ul(nil, func(n *Node) {
+					n.Li(nil, Text("a"))
+					n.Li(nil, func(n *Node) {
+						n.Span(nil, Text("b"), func(n *Node) {
+							n.Div(nil, Text("c"))
+						})
+					})
+				})
+			},
+		},
+		{
+			expected: "<!DOCTYPE html>\n<html>\n  <head>\n    <meta charset=\"utf-8\" />\n    <title>T</title>\n    <script>var a = 1;</script>\n  </head>\n  <body>\n    <div></div>\n  </body>\n</html>\n",
+			component: func(n *Node) {
+				n.DocType()
+				n.HTML(nil, func(n *Node) {
+					n.Head(nil, func(n *Node) {
+						n.Meta(S(`charset="utf-8"`))
+						n.Title(nil, Text("T"))
+						n.Script(nil, Raw("var a = 1;"))
+					})
+					n.Body(nil, func(n *Node) {
+						n.Div(nil)
+					})
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("expects: %v", tc.expected)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			tc.component(&Node{Writer: b, DevMode: true})
+			assert.Equal(t, tc.expected, b.String())
+
+			prod := &strings.Builder{}
+			tc.component(&Node{Writer: prod})
+			assert.Equal(t, renderedDOM(t, prod.String()), renderedDOM(t, b.String()))
+		})
+	}
+}
+
+// renderedDOM parses s and drops the whitespace a browser would not render:
+// runs of whitespace collapse to one space, and whitespace at the edges of
+// blocks and between children of containers disappears.
+func renderedDOM(t *testing.T, s string) string {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(s))
+	assert.NoError(t, err)
+	isBlock := func(node *html.Node) bool {
+		return node == nil || node.Type == html.ElementNode && blockTags[node.Data]
+	}
+	var walk func(*html.Node, bool)
+	walk = func(node *html.Node, pre bool) {
+		pre = pre || node.Type == html.ElementNode && preserveSpaceTags[node.Data]
+		for c := node.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.TextNode && !pre {
+				c.Data = collapseSpace(c.Data)
+				if node.Type == html.ElementNode && containerTags[node.Data] && strings.TrimSpace(c.Data) == "" {
+					c.Data = ""
+				}
+				if isBlock(node) && isBlock(c.PrevSibling) {
+					c.Data = strings.TrimLeft(c.Data, " ")
+				}
+				if isBlock(node) && isBlock(c.NextSibling) {
+					c.Data = strings.TrimRight(c.Data, " ")
+				}
+				if c.Data == "" {
+					node.RemoveChild(c)
+				}
+			}
+			walk(c, pre)
+			c = next
+		}
+	}
+	walk(doc, false)
+	b := &strings.Builder{}
+	assert.NoError(t, html.Render(b, doc))
+	return b.String()
+}
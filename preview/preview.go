@@ -0,0 +1,252 @@
+// Package preview serves a gallery of mx components for development: each
+// component is registered with named variants, listed on an index page and
+// rendered in isolation, optionally in DevMode with the validation and
+// accessibility reports. Pages reload when the server restarts, so running the
+// gallery under a file watcher reloads it on every rebuild.
+//
+//	g := preview.New("UI")
+//	g.Add("Button",
+//		preview.Variant{Name: "Primary", Render: ui.Button("Save", ui.Primary)},
+//		preview.Variant{Name: "Disabled", Render: ui.Button("Save", ui.Disabled)},
+//	)
+//	http.ListenAndServe("localhost:6060", g)
+package preview
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// Gallery is an http.Handler serving the registered components. Add
+	// components before serving.
+	Gallery struct {
+		Title      string
+		Head       func(*mx.Node) // rendered in the <head> of component pages, e.g. for stylesheets
+		components []*Component
+		mux        *http.ServeMux
+		version    string
+	}
+
+	// Component is a component with its variants.
+	Component struct {
+		Name     string
+		Variants []Variant
+	}
+
+	// Variant renders a component with a fixture, e.g. a button in its
+	// disabled state.
+	Variant struct {
+		Name   string
+		Render func(*mx.Node)
+	}
+)
+
+// Reloads the page when the version of the server changes
+const reloadScript = `(function () {
+  var root = document.currentScript.dataset.root, version = null;
+  setInterval(function () {
+    fetch(root + "version").then(function (r) { return r.text(); }).then(function (v) {
+      if (version !== null && v !== version) location.reload();
+      version = v;
+    }).catch(function () {});
+  }, 1000);
+})();`
+
+const galleryStyle = `body{font:14px system-ui,sans-serif;margin:0;display:flex;height:100vh}
+nav{width:220px;padding:1rem;border-right:1px solid #ddd;overflow:auto}
+nav ul{list-style:none;padding-left:1rem}
+main{flex:1;display:flex;flex-direction:column;padding:1rem}
+iframe{flex:1;border:1px dashed #bbb;background:#fff}
+.current{font-weight:bold}
+.problems li{color:#a00}`
+
+// New creates an empty gallery.
+func New(title string) *Gallery {
+	g := &Gallery{
+		Title:   title,
+		mux:     http.NewServeMux(),
+		version: strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	g.mux.HandleFunc("GET /{$}", g.serveIndex)
+	g.mux.HandleFunc("GET /c/{component}/{variant}", g.serveViewer)
+	g.mux.HandleFunc("GET /render/{component}/{variant}", g.serveRender)
+	g.mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = io.WriteString(w, g.version)
+	})
+	return g
+}
+
+// Add registers a component with its variants.
+func (g *Gallery) Add(name string, variants ...Variant) {
+	g.components = append(g.components, &Component{Name: name, Variants: variants})
+}
+
+// Components returns the registered components.
+func (g *Gallery) Components() []*Component {
+	return g.components
+}
+
+// ServeHTTP serves the index at /, the page of each variant at
+// /c/{component}/{variant} and the variant alone at
+// /render/{component}/{variant}. Add ?dev to render in DevMode.
+func (g *Gallery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// find returns the variant of a component.
+func (g *Gallery) find(r *http.Request) (*Component, *Variant) {
+	for _, c := range g.components {
+		if c.Name != r.PathValue("component") {
+			continue
+		}
+		for i := range c.Variants {
+			if c.Variants[i].Name == r.PathValue("variant") {
+				return c, &c.Variants[i]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// serveIndex lists the components.
+func (g *Gallery) serveIndex(w http.ResponseWriter, r *http.Request) {
+	g.page(w, "./", g.Title, func(n *mx.Node) {
+		n.H1(nil, mx.Text(g.Title))
+		n.P(nil, mx.Textf("%d components. Pick a variant to preview it.", len(g.components)))
+	})
+}
+
+// serveViewer shows a variant in an iframe, with the problems found in
+// DevMode.
+func (g *Gallery) serveViewer(w http.ResponseWriter, r *http.Request) {
+	c, v := g.find(r)
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	dev := r.URL.Query().Has("dev")
+	self := "./" + url.PathEscape(v.Name)
+	path := url.PathEscape(c.Name) + "/" + url.PathEscape(v.Name)
+	query := ""
+	if dev {
+		query = "?dev"
+	}
+
+	g.page(w, "../../", c.Name+" · "+v.Name, func(n *mx.Node) {
+		n.H1(nil, mx.Text(c.Name+" · "+v.Name))
+		n.P(nil, func(n *mx.Node) {
+			if dev {
+				n.A(mx.M{"href": self}, mx.Text("Production mode"))
+			} else {
+				n.A(mx.M{"href": self + "?dev"}, mx.Text("DevMode"))
+			}
+			mx.Text(" · ")(n)
+			n.A(mx.Slice{mx.M{"href": "../../render/" + path + query}, mx.S(`target="_blank"`)}, mx.Text("Open alone"))
+		})
+		if dev {
+			problems(n, v)
+		}
+		n.IFrame(mx.Slice{mx.M{"src": "../../render/" + path + query}, mx.M{"title": c.Name + " " + v.Name}}, nil)
+	})
+}
+
+// serveRender renders a variant alone.
+func (g *Gallery) serveRender(w http.ResponseWriter, r *http.Request) {
+	c, v := g.find(r)
+	if v == nil {
+		http.NotFound(w, r)
+		return
+	}
+	dev := r.URL.Query().Has("dev")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	n := &mx.Node{Writer: w, DevMode: dev, Trace: dev}
+	n.DocType()
+	n.HTML(mx.S(`lang="en"`), func(n *mx.Node) {
+		n.Head(nil, func(n *mx.Node) {
+			n.Meta(mx.S(`charset="utf-8"`))
+			n.Title(nil, mx.Text(c.Name+" · "+v.Name))
+			if g.Head != nil {
+				g.Head(n)
+			}
+		})
+		n.Body(nil, v.Render, reload("../../"))
+	})
+}
+
+// page writes a gallery page with the component list and content.
+func (g *Gallery) page(w http.ResponseWriter, root, title string, content func(*mx.Node)) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	n := &mx.Node{Writer: w}
+	n.DocType()
+	n.HTML(mx.S(`lang="en"`), func(n *mx.Node) {
+		n.Head(nil, func(n *mx.Node) {
+			n.Meta(mx.S(`charset="utf-8"`))
+			n.Title(nil, mx.Text(title))
+			n.Style(nil, mx.Raw(galleryStyle))
+		})
+		n.Body(nil, func(n *mx.Node) {
+			n.Nav(nil, func(n *mx.Node) {
+				n.A(mx.M{"href": root}, mx.Text(g.Title))
+				n.Ul(nil, func(n *mx.Node) {
+					for _, c := range g.components {
+						n.Li(nil, mx.Text(c.Name), func(n *mx.Node) {
+							n.Ul(nil, func(n *mx.Node) {
+								for _, v := range c.Variants {
+									href := root + "c/" + url.PathEscape(c.Name) + "/" + url.PathEscape(v.Name)
+									n.Li(classIf(c.Name+" · "+v.Name == title, "current"), func(n *mx.Node) {
+										n.A(mx.M{"href": href}, mx.Text(v.Name))
+									})
+								}
+							})
+						})
+					}
+				})
+			})
+			n.Main(nil, content)
+		}, reload(root))
+	})
+}
+
+// problems lists the content model violations and accessibility issues of a
+// variant.
+func problems(n *mx.Node, v *Variant) {
+	check := &mx.Node{Writer: io.Discard, DevMode: true, Validate: true, A11y: true}
+	v.Render(check)
+	messages := []string{}
+	for _, v := range mx.Violations(check) {
+		messages = append(messages, v.Error())
+	}
+	for _, i := range mx.A11yIssues(check) {
+		messages = append(messages, i.Error())
+	}
+	if len(messages) == 0 {
+		return
+	}
+	n.Ul(mx.S(`class="problems"`), func(n *mx.Node) {
+		for _, m := range messages {
+			n.Li(nil, mx.Text(m))
+		}
+	})
+}
+
+// reload renders the script that reloads the page on restarts.
+func reload(root string) func(*mx.Node) {
+	return func(n *mx.Node) {
+		n.Script(mx.M{"data-root": root}, mx.Raw(reloadScript))
+	}
+}
+
+// classIf renders a class attribute when cond is true.
+func classIf(cond bool, class string) mx.Attr {
+	if !cond {
+		return nil
+	}
+	return mx.M{"class": class}
+}
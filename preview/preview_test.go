@@ -0,0 +1,109 @@
+package preview
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	previewTestCase struct {
+		path     string
+		status   int
+		contains []string
+	}
+)
+
+func gallery() *Gallery {
+	g := New("UI")
+	g.Head = func(n *mx.Node) {
+		n.Link(mx.S(`rel="stylesheet" href="/app.css"`))
+	}
+	g.Add("Button",
+		Variant{Name: "Primary", Render: func(n *mx.Node) {
+			n.Button(mx.S(`class="primary"`), mx.Text("Save"))
+		}},
+		Variant{Name: "Icon only", Render: func(n *mx.Node) {
+			n.Button(nil, func(n *mx.Node) { n.Img(mx.S(`src="/save.svg"`)) })
+		}},
+	)
+	return g
+}
+
+func TestGallery(t *testing.T) {
+	testCases := []previewTestCase{
+		{
+			path:   "/",
+			status: http.StatusOK,
+			contains: []string{
+				"<h1>UI</h1>",
+				`<a href="./c/Button/Primary">Primary</a>`,
+				`<a href="./c/Button/Icon%20only">Icon only</a>`,
+				`<script data-root="./">`,
+			},
+		},
+		{
+			path:   "/c/Button/Primary",
+			status: http.StatusOK,
+			contains: []string{
+				`<li class="current"><a href="../../c/Button/Primary">Primary</a></li>`,
+				`<a href="./Primary?dev">DevMode</a>`,
+				`<iframe src="../../render/Button/Primary" title="Button Primary"></iframe>`,
+				`<a href="../../render/Button/Primary" target="_blank">Open alone</a>`,
+			},
+		},
+		{
+			path:   "/c/Button/Icon%20only?dev",
+			status: http.StatusOK,
+			contains: []string{
+				`<a href="./Icon%20only">Production mode</a>`,
+				`<iframe src="../../render/Button/Icon%20only?dev"`,
+				`<ul class="problems">`,
+				"missing alt attribute [image-alt]",
+				"button without an accessible name [button-name]",
+			},
+		},
+		{
+			path:   "/render/Button/Primary",
+			status: http.StatusOK,
+			contains: []string{
+				`<!DOCTYPE html><html lang="en"><head><meta charset="utf-8" /><title>Button · Primary</title>` +
+					`<link rel="stylesheet" href="/app.css" /></head><body><button class="primary">Save</button>`,
+			},
+		},
+		{
+			path:     "/render/Button/Primary?dev",
+			status:   http.StatusOK,
+			contains: []string{`<button class="primary" data-mx-component="preview.gallery"`},
+		},
+		{path: "/c/Button/Secondary", status: http.StatusNotFound},
+		{path: "/render/Card/Primary", status: http.StatusNotFound},
+	}
+
+	g := gallery()
+	for _, tc := range testCases {
+		name := fmt.Sprintf("serves '%v'", tc.path)
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.status, w.Code)
+			for _, s := range tc.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+		})
+	}
+
+	t.Run("serves the version for reloads", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+		body, _ := io.ReadAll(w.Body)
+		assert.Equal(t, g.version, string(body))
+		assert.NotEqual(t, g.version, New("UI").version)
+	})
+}
@@ -0,0 +1,226 @@
+package mx
+
+import (
+	"fmt"
+	"io"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Profiler records how much time, output and allocations each element
+	// subtree takes to render. Attach it to Node.Profiler; it can be shared by
+	// concurrent renders. Allocations are read from runtime/metrics around
+	// each element, so they include other goroutines' and are only as precise
+	// as the runtime's per-thread allocation caches.
+	Profiler struct {
+		mu      sync.Mutex
+		samples map[string]*Sample
+	}
+
+	// Sample aggregates the renders of the elements with the same stack, e.g.
+	// "ui.Page;main;ui.Card;div". Components appear in the stack before the
+	// root element they render.
+	Sample struct {
+		Stack      string
+		Count      int           // times the subtree was rendered
+		Time       time.Duration // wall time, including children
+		SelfTime   time.Duration // wall time, excluding children
+		Bytes      int64         // bytes written, including children
+		SelfBytes  int64         // bytes written, excluding children
+		Allocs     uint64        // heap allocations, including children
+		SelfAllocs uint64        // heap allocations, excluding children
+	}
+
+	// Metric selects the value exported by Profiler.WriteFolded.
+	Metric int
+
+	// span is an element being profiled.
+	span struct {
+		stack       string
+		start       time.Time
+		bytes       int64
+		allocs      uint64
+		childTime   time.Duration
+		childBytes  int64
+		childAllocs uint64
+	}
+)
+
+const (
+	ProfileTime   Metric = iota // self wall time in nanoseconds
+	ProfileBytes                // self bytes written
+	ProfileAllocs               // self heap allocations
+)
+
+// NewProfiler creates an empty profiler.
+func NewProfiler() *Profiler {
+	return &Profiler{samples: map[string]*Sample{}}
+}
+
+// Samples returns the recorded samples sorted by stack.
+func (p *Profiler) Samples() []Sample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	samples := make([]Sample, 0, len(p.samples))
+	for _, s := range p.samples {
+		samples = append(samples, *s)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Stack < samples[j].Stack
+	})
+	return samples
+}
+
+// Reset discards the recorded samples.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.samples = map[string]*Sample{}
+}
+
+// WriteFolded writes the samples in the folded stacks format read by
+// flamegraph.pl, speedscope and similar tools: one "stack value" line per
+// stack, with the self value of the chosen metric.
+func (p *Profiler) WriteFolded(w io.Writer, m Metric) error {
+	for _, s := range p.Samples() {
+		var v uint64
+		switch m {
+		case ProfileTime:
+			v = uint64(s.SelfTime)
+		case ProfileBytes:
+			v = uint64(s.SelfBytes)
+		case ProfileAllocs:
+			v = s.SelfAllocs
+		}
+		if v == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", s.Stack, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Overlay renders the slowest subtrees recorded so far in a panel fixed to the
+// corner of the page, at most limit of them, or all if limit <= 0. It renders
+// nothing outside dev mode. Place it at the end of <body> to include the rest
+// of the page.
+func (p *Profiler) Overlay(limit int) func(*Node) {
+	return func(n *Node) {
+		if !n.DevMode {
+			return
+		}
+		samples := p.Samples()
+		sort.SliceStable(samples, func(i, j int) bool {
+			return samples[i].Time > samples[j].Time
+		})
+		if limit > 0 && len(samples) > limit {
+			samples = samples[:limit]
+		}
+		style := `position:fixed;bottom:0;right:0;z-index:2147483647;max-height:50vh;overflow:auto;` +
+			`background:#fff;color:#000;font:12px monospace;border:1px solid #888;opacity:.9`
+		n.Div(M{"style": style, "data-mx-profile": ""}, func(n *Node) {
+			n.Table(nil, func(n *Node) {
+				n.THead(nil, func(n *Node) {
+					n.Tr(nil, func(n *Node) {
+						for _, h := range []string{"subtree", "count", "time", "self", "bytes", "allocs"} {
+							n.Th(nil, Text(h))
+						}
+					})
+				})
+				n.TBody(nil, func(n *Node) {
+					for _, s := range samples {
+						n.Tr(nil, func(n *Node) {
+							n.Td(M{"title": s.Stack}, Text(lastFrames(s.Stack, 3)))
+							n.Td(nil, Textf("%d", s.Count))
+							n.Td(nil, Text(s.Time.String()))
+							n.Td(nil, Text(s.SelfTime.String()))
+							n.Td(nil, Textf("%d", s.Bytes))
+							n.Td(nil, Textf("%d", s.Allocs))
+						})
+					}
+				})
+			})
+		})
+	}
+}
+
+// startSpan starts profiling an element.
+func (n *Node) startSpan(f frame, root bool) {
+	stack := f.tag
+	if root {
+		stack = f.component + ";" + stack
+	}
+	if len(n.spans) > 0 {
+		stack = n.spans[len(n.spans)-1].stack + ";" + stack
+	}
+	n.spans = append(n.spans, span{
+		stack:  stack,
+		bytes:  n.stats.Bytes,
+		allocs: n.mallocs(),
+		start:  time.Now(),
+	})
+}
+
+// endSpan records the element profiled by the innermost span.
+func (n *Node) endSpan() {
+	elapsed := time.Since(n.spans[len(n.spans)-1].start)
+	allocs := n.mallocs()
+	s := n.spans[len(n.spans)-1]
+	n.spans = n.spans[:len(n.spans)-1]
+
+	bytes := n.stats.Bytes - s.bytes
+	allocs -= s.allocs
+	if len(n.spans) > 0 {
+		parent := &n.spans[len(n.spans)-1]
+		parent.childTime += elapsed
+		parent.childBytes += bytes
+		parent.childAllocs += allocs
+	}
+
+	p := n.Profiler
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.samples == nil {
+		p.samples = map[string]*Sample{}
+	}
+	sample, ok := p.samples[s.stack]
+	if !ok {
+		sample = &Sample{Stack: s.stack}
+		p.samples[s.stack] = sample
+	}
+	sample.Count++
+	sample.Time += elapsed
+	sample.SelfTime += elapsed - s.childTime
+	sample.Bytes += bytes
+	sample.SelfBytes += bytes - s.childBytes
+	sample.Allocs += allocs
+	sample.SelfAllocs += allocs - s.childAllocs
+}
+
+// mallocs returns the number of heap allocations so far. Unlike
+// runtime.ReadMemStats, it doesn't stop the world.
+func (n *Node) mallocs() uint64 {
+	if n.allocs == nil {
+		n.allocs = []metrics.Sample{{Name: "/gc/heap/allocs:objects"}}
+	}
+	metrics.Read(n.allocs)
+	if n.allocs[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return n.allocs[0].Value.Uint64()
+}
+
+// lastFrames returns the innermost frames of a folded stack.
+func lastFrames(stack string, k int) string {
+	frames := strings.Split(stack, ";")
+	if len(frames) <= k {
+		return stack
+	}
+	return "…;" + strings.Join(frames[len(frames)-k:], ";")
+}
@@ -0,0 +1,90 @@
+package mx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sink keeps the allocations of tests on the heap
+var sink [][]byte
+
+func TestProfiler(t *testing.T) {
+	p := NewProfiler()
+	for range 2 {
+		tracePage(&Node{Writer: &strings.Builder{}, Profiler: p})
+	}
+
+	t.Run("records samples per stack", func(t *testing.T) {
+		samples := p.Samples()
+		stacks := []string{}
+		for _, s := range samples {
+			stacks = append(stacks, s.Stack)
+			assert.Equal(t, 2, s.Count)
+		}
+		assert.Equal(t, []string{
+			"mx.tracePage;main",
+			"mx.tracePage;main;hr",
+			"mx.tracePage;main;mx.traceCard;div",
+			"mx.tracePage;main;mx.traceCard;div;p",
+		}, stacks)
+
+		main, div, p := samples[0], samples[2], samples[3]
+		assert.Equal(t, int64(2*len(`<main><div class="card"><p>body</p></div><hr /></main>`)), main.Bytes)
+		assert.Equal(t, int64(2*len(`<main></main>`)), main.SelfBytes)
+		assert.Equal(t, int64(2*len(`<div class="card"></div>`)), div.SelfBytes)
+		assert.Equal(t, int64(2*len(`<p>body</p>`)), p.Bytes)
+		assert.GreaterOrEqual(t, main.Time, div.Time)
+		assert.Equal(t, main.Time, main.SelfTime+div.Time+samples[1].Time)
+	})
+
+	t.Run("counts allocations", func(t *testing.T) {
+		p := NewProfiler()
+		n := &Node{Writer: &strings.Builder{}, Profiler: p}
+		n.Div(nil, func(n *Node) {
+			for range 64 {
+				sink = append(sink, make([]byte, 64<<10)) // large objects are counted right away
+			}
+		})
+		sink = nil
+		samples := p.Samples()
+		if assert.Len(t, samples, 1) {
+			assert.GreaterOrEqual(t, samples[0].Allocs, uint64(64))
+			assert.Equal(t, samples[0].Allocs, samples[0].SelfAllocs)
+		}
+	})
+
+	t.Run("writes folded stacks", func(t *testing.T) {
+		b := &strings.Builder{}
+		assert.NoError(t, p.WriteFolded(b, ProfileBytes))
+		assert.Equal(t, "mx.tracePage;main 26\n"+
+			"mx.tracePage;main;hr 12\n"+
+			"mx.tracePage;main;mx.traceCard;div 48\n"+
+			"mx.tracePage;main;mx.traceCard;div;p 22\n", b.String())
+	})
+
+	t.Run("renders an overlay in dev mode", func(t *testing.T) {
+		b := &strings.Builder{}
+		p.Overlay(2)(&Node{Writer: b})
+		assert.Empty(t, b.String())
+
+		p.Overlay(2)(&Node{Writer: b, DevMode: true})
+		assert.Contains(t, b.String(), "data-mx-profile")
+		assert.Contains(t, b.String(), `<td title="mx.tracePage;main">mx.tracePage;main</td>`)
+		assert.Equal(t, 2, strings.Count(b.String(), "<td title="))
+	})
+
+	t.Run("renders every subtree without a limit", func(t *testing.T) {
+		for _, limit := range []int{0, -1} {
+			b := &strings.Builder{}
+			p.Overlay(limit)(&Node{Writer: b, DevMode: true})
+			assert.Equal(t, len(p.Samples()), strings.Count(b.String(), "<td title="))
+		}
+	})
+
+	t.Run("resets", func(t *testing.T) {
+		p.Reset()
+		assert.Empty(t, p.Samples())
+	})
+}
@@ -0,0 +1,244 @@
+package mx
+
+import (
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Policy configures what Sanitize keeps from untrusted HTML.
+type Policy struct {
+	Elements        map[string][]string // allowed elements and the attributes allowed on each
+	GlobalAttrs     []string            // attributes allowed on every allowed element
+	URLSchemes      []string            // schemes allowed in URL-valued attributes (relative URLs are always allowed)
+	RequireNoopener bool                // adds rel="noopener" to links
+}
+
+// Elements whose content is dropped along with them when not allowed
+var dropContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"noscript": true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"svg":      true,
+	"math":     true,
+	"textarea": true,
+	"select":   true,
+	"title":    true,
+}
+
+// Attributes that hold URLs in untrusted HTML, checked against the policy's
+// schemes. srcset holds several, with their descriptors, and ping a list.
+var sanitizedURLAttrs = map[string]bool{
+	"action": true, "archive": true, "background": true, "cite": true, "classid": true,
+	"codebase": true, "data": true, "formaction": true, "href": true, "icon": true,
+	"longdesc": true, "manifest": true, "ping": true, "poster": true, "profile": true,
+	"src": true, "srcset": true, "usemap": true,
+}
+
+// UGCPolicy returns a policy suited for user-generated content such as
+// rendered Markdown comments: text formatting, lists, tables, links and images.
+func UGCPolicy() *Policy {
+	return &Policy{
+		Elements: map[string][]string{
+			"a": {"href", "title", "rel"}, "abbr": {"title"}, "b": nil, "blockquote": {"cite"},
+			"br": nil, "code": nil, "dd": nil, "del": nil, "div": nil, "dl": nil, "dt": nil,
+			"em": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+			"hr": nil, "i": nil, "img": {"src", "alt", "title", "width", "height"}, "ins": nil,
+			"kbd": nil, "li": nil, "mark": nil, "ol": {"start"}, "p": nil, "pre": nil, "q": {"cite"},
+			"s": nil, "small": nil, "span": nil, "strong": nil, "sub": nil, "sup": nil,
+			"table": nil, "tbody": nil, "td": {"colspan", "rowspan", "align"}, "tfoot": nil,
+			"th": {"colspan", "rowspan", "align", "scope"}, "thead": nil, "tr": nil, "u": nil, "ul": nil,
+		},
+		GlobalAttrs:     []string{"id", "class", "lang", "dir"},
+		URLSchemes:      []string{"http", "https", "mailto"},
+		RequireNoopener: true,
+	}
+}
+
+// Sanitize parses untrusted HTML and renders only the elements and attributes
+// allowed by the policy. Disallowed elements are unwrapped, keeping their
+// text, except for those like <script> whose content is dropped too.
+func Sanitize(p *Policy, untrusted string) func(*Node) {
+	return func(n *Node) {
+		sanitize(n, p, untrusted, nil)
+	}
+}
+
+// SanitizeParts is like Sanitize for untrusted HTML split around trusted
+// components: it renders untrusted[0], trusted[0], untrusted[1], and so on.
+// The untrusted parts are parsed together, so a tag opened in one and closed
+// in another, e.g. "<b>", a component, "</b>", wraps the components between
+// them. The components don't go through the policy, but they are dropped if
+// they end up in dropped content, e.g. inside <script>.
+func SanitizeParts(p *Policy, untrusted []string, trusted []func(*Node)) func(*Node) {
+	return func(n *Node) {
+		b := &strings.Builder{}
+		for i := 0; i < len(untrusted) || i < len(trusted); i++ {
+			if i < len(untrusted) {
+				b.WriteString(stripSlots(untrusted[i]))
+			}
+			if i < len(trusted) {
+				b.WriteString(string(slotStart) + strconv.Itoa(i) + string(slotEnd))
+			}
+		}
+		sanitize(n, p, b.String(), trusted)
+	}
+}
+
+// sanitize renders untrusted HTML allowed by p, and slots in place of their
+// placeholders.
+func sanitize(n *Node, p *Policy, untrusted string, slots []func(*Node)) {
+	ctx := &xhtml.Node{Type: xhtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := xhtml.ParseFragment(strings.NewReader(untrusted), ctx)
+	if err != nil {
+		if n.err == nil {
+			n.err = err
+		}
+		return
+	}
+	s := &sanitizer{policy: p, schemes: map[string]bool{}, slots: slices.Clone(slots)}
+	for _, scheme := range p.URLSchemes {
+		s.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, node := range nodes {
+		s.render(n, node)
+	}
+}
+
+// SanitizeHTML sanitizes untrusted HTML into a string that can be passed to Raw.
+func SanitizeHTML(p *Policy, untrusted string) (HTML, error) {
+	var b strings.Builder
+	n := &Node{Writer: &b}
+	Sanitize(p, untrusted)(n)
+	return HTML(b.String()), Error(n)
+}
+
+type sanitizer struct {
+	policy  *Policy
+	schemes map[string]bool
+	slots   []func(*Node) // trusted components of SanitizeParts, rendered once each
+}
+
+// Private use characters that delimit the placeholders of SanitizeParts, e.g.
+// "\ue0003\ue001" for the fourth component. They are removed from the
+// untrusted parts, so they can't be forged.
+const (
+	slotStart = '\ue000'
+	slotEnd   = '\ue001'
+)
+
+// stripSlots removes placeholder delimiters from s.
+func stripSlots(s string) string {
+	return strings.NewReplacer(string(slotStart), "", string(slotEnd), "").Replace(s)
+}
+
+// render writes a parsed node if the policy allows it.
+func (s *sanitizer) render(n *Node, node *xhtml.Node) {
+	switch node.Type {
+	case xhtml.TextNode:
+		s.text(n, node.Data)
+	case xhtml.ElementNode:
+		allowed, ok := s.policy.Elements[node.Data]
+		if !ok {
+			if !dropContentTags[node.Data] {
+				s.renderChildren(n, node)
+			}
+			return
+		}
+		n.el(node.Data, S(s.attributes(node, allowed)), func(n *Node) {
+			s.renderChildren(n, node)
+		})
+	}
+}
+
+// text renders text, with the slots whose placeholders it has.
+func (s *sanitizer) text(n *Node, text string) {
+	for len(s.slots) > 0 {
+		start := strings.IndexRune(text, slotStart)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexRune(text[start:], slotEnd)
+		if end < 0 {
+			break
+		}
+		Text(text[:start])(n)
+		i, err := strconv.Atoi(text[start+len(string(slotStart)) : start+end])
+		if err == nil && i < len(s.slots) && s.slots[i] != nil {
+			s.slots[i](n)
+			s.slots[i] = nil
+		}
+		text = text[start+end+len(string(slotEnd)):]
+	}
+	Text(text)(n)
+}
+
+func (s *sanitizer) renderChildren(n *Node, node *xhtml.Node) {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		s.render(n, c)
+	}
+}
+
+// attributes renders the allowed attributes of node, dropping URLs with
+// disallowed schemes.
+func (s *sanitizer) attributes(node *xhtml.Node, allowed []string) string {
+	var b strings.Builder
+	link := node.Data == "a" && s.policy.RequireNoopener
+	rel := []string{}
+	for _, a := range node.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) && !slices.Contains(s.policy.GlobalAttrs, a.Key) {
+			continue
+		}
+		if sanitizedURLAttrs[a.Key] && !s.safeURLs(a.Key, a.Val) {
+			continue
+		}
+		if link && a.Key == "rel" {
+			rel = strings.Fields(a.Val)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.Key + `="` + html.EscapeString(stripSlots(a.Val)) + `"`)
+	}
+	if link {
+		if !slices.Contains(rel, "noopener") {
+			rel = append(rel, "noopener")
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(`rel="` + html.EscapeString(strings.Join(rel, " ")) + `"`)
+	}
+	return b.String()
+}
+
+// safeURLs checks the URLs of attribute key against the policy's schemes.
+func (s *sanitizer) safeURLs(key, value string) bool {
+	urls := []string{value}
+	switch key {
+	case "srcset":
+		urls = nil
+		for c := range strings.SplitSeq(value, ",") {
+			if fields := strings.Fields(c); len(fields) > 0 {
+				urls = append(urls, fields[0])
+			}
+		}
+	case "ping":
+		urls = strings.Fields(value)
+	}
+	for _, u := range urls {
+		if !isSafeURL(u, s.schemes) {
+			return false
+		}
+	}
+	return true
+}
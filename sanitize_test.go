@@ -0,0 +1,86 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	sanitizeTestCase struct {
+		input    string
+		expected string
+	}
+)
+
+func TestSanitize(t *testing.T) {
+	testCases := []sanitizeTestCase{
+		{input: "plain & simple", expected: "plain &amp; simple"},
+		{input: "<p>Hello, <b>world</b>!</p>", expected: "<p>Hello, <b>world</b>!</p>"},
+		{input: `<p onclick="steal()">hi</p>`, expected: "<p>hi</p>"},
+		{input: "<script>alert(1)</script>ok", expected: "ok"},
+		{input: "<style>body{}</style><p>ok</p>", expected: "<p>ok</p>"},
+		{input: "<blink>still <i>here</i></blink>", expected: "still <i>here</i>"},
+		{input: "<!-- comment --><br>", expected: "<br />"},
+		{input: `<img src="javascript:alert(1)" alt="x">`, expected: `<img alt="x" />`},
+		{input: `<img src="/a.png" alt="a" onerror="steal()">`, expected: `<img src="/a.png" alt="a" />`},
+		{input: `<a href="https://example.com">x</a>`, expected: `<a href="https://example.com" rel="noopener">x</a>`},
+		{input: `<a href="vbscript:x" rel="nofollow">x</a>`, expected: `<a rel="nofollow noopener">x</a>`},
+		{input: `<a href="/x" rel="noopener">x</a>`, expected: `<a href="/x" rel="noopener">x</a>`},
+		{input: `<div class="a" style="color:red">x</div>`, expected: `<div class="a">x</div>`},
+		{input: `<p title="&quot;quoted&quot;">x</p>`, expected: `<p>x</p>`},
+		{input: `<abbr title="&quot;q&quot;">x</abbr>`, expected: `<abbr title="&#34;q&#34;">x</abbr>`},
+		{input: "<p>unclosed <em>tags", expected: "<p>unclosed <em>tags</em></p>"},
+		{input: `<blockquote cite="javascript:x">q</blockquote>`, expected: `<blockquote>q</blockquote>`},
+		{input: `<q cite="/source">q</q>`, expected: `<q cite="/source">q</q>`},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("sanitizes: %v", tc.input)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			n := &Node{Writer: b}
+			Sanitize(UGCPolicy(), tc.input)(n)
+			assert.NoError(t, Error(n))
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+
+	t.Run("custom policy", func(t *testing.T) {
+		p := &Policy{
+			Elements:   map[string][]string{"a": {"href"}},
+			URLSchemes: []string{"https"},
+		}
+		h, err := SanitizeHTML(p, `<a href="http://x" id="y">a</a><a href="https://x">b</a>`)
+		assert.NoError(t, err)
+		assert.Equal(t, HTML(`<a>a</a><a href="https://x">b</a>`), h)
+	})
+
+	t.Run("renders trusted parts as they are", func(t *testing.T) {
+		b := &strings.Builder{}
+		n := &Node{Writer: b}
+		link := func(n *Node) { n.A(S(`href="/x" target="_blank" data-id="1"`), Text("link")) }
+		SanitizeParts(UGCPolicy(), []string{`<b onclick="x">`, "</b> and <script>", "</script> \ue0000\ue001 <i title=\"\ue0001\ue001\">i</i>"},
+			[]func(*Node){link, Text("dropped")})(n)
+		assert.NoError(t, Error(n))
+		assert.Equal(t, `<b><a href="/x" target="_blank" data-id="1">link</a></b> and  0 <i>i</i>`, b.String())
+	})
+
+	t.Run("checks every URL attribute", func(t *testing.T) {
+		p := &Policy{
+			Elements: map[string][]string{
+				"video": {"poster", "src"}, "img": {"srcset", "longdesc"}, "a": {"ping", "href"},
+			},
+			URLSchemes: []string{"https"},
+		}
+		h, err := SanitizeHTML(p, `<video poster="javascript:x" src="/v.mp4"></video>`+
+			`<img srcset="/a.png 1x, javascript:x 2x" longdesc="data:text/html,x">`+
+			`<img srcset="/a.png 1x, https://x/b.png 2x">`+
+			`<a ping="/p javascript:x" href="/a">a</a><a ping="/p https://x/p">b</a>`)
+		assert.NoError(t, err)
+		assert.Equal(t, HTML(`<video src="/v.mp4"></video><img /><img srcset="/a.png 1x, https://x/b.png 2x" />`+
+			`<a href="/a">a</a><a ping="/p https://x/p">b</a>`), h)
+	})
+}
@@ -0,0 +1,80 @@
+package mx
+
+import (
+	"errors"
+	"html"
+)
+
+// ErrTargetNotFound is returned by Error when no element or Part matched
+// Node.Target.
+var ErrTargetNotFound = errors.New("mx: nothing matches Node.Target")
+
+// selection is the progress of a Node with Target through the page.
+type selection int
+
+const (
+	selectBefore selection = iota // looking for the selected subtree, output is discarded
+	selectInside                  // writing the selected subtree
+	selectDone                    // the selected subtree was written, the rest is skipped
+)
+
+// Part names a fragment of a component, so it can be rendered alone with
+// Node.Target. It renders its children as they are, without a wrapper element.
+func Part(name string, children ...func(*Node)) func(*Node) {
+	return func(n *Node) {
+		if n.skipping() {
+			return
+		}
+		selected := n.Target != "" && n.Target == name && n.selection == selectBefore
+		if selected {
+			n.startSelection()
+			n.midLine = false
+			defer n.endSelection()
+		}
+		for _, child := range children {
+			if child != nil {
+				child(n)
+			}
+		}
+	}
+}
+
+// suppressed checks if output must be discarded because it is outside the
+// selected subtree.
+func (n *Node) suppressed() bool {
+	return n.Target != "" && n.selection != selectInside
+}
+
+// skipping checks if the selected subtree was written, so the rest of the
+// page needn't be rendered.
+func (n *Node) skipping() bool {
+	return n.Target != "" && n.selection == selectDone
+}
+
+// selects checks if the element with attrs is the root of the selected
+// subtree.
+func (n *Node) selects(attrs string) bool {
+	if n.Target == "" || n.selection != selectBefore {
+		return false
+	}
+	for _, a := range parseAttrs(attrs) {
+		if a.name == "id" && html.UnescapeString(a.value) == n.Target {
+			return true
+		}
+	}
+	return false
+}
+
+// startSelection starts writing the selected subtree, as if it were at the
+// top level.
+func (n *Node) startSelection() {
+	n.selection = selectInside
+	n.selectIndent = n.indent
+	n.indent = 0
+}
+
+// endSelection ends the selected subtree.
+func (n *Node) endSelection() {
+	n.selection = selectDone
+	n.indent = n.selectIndent
+}
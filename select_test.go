@@ -0,0 +1,100 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	targetTestCase struct {
+		target   string
+		devMode  bool
+		minify   bool
+		expected string
+	}
+)
+
+func targetPage(footers *int) func(*Node) {
+	return func(n *Node) {
+		n.HTML(nil, func(n *Node) {
+			n.Body(nil, func(n *Node) {
+				n.H1(nil, Text("Todos"))
+				n.Main(nil, func(n *Node) {
+					n.Ul(M{"id": "list"}, func(n *Node) {
+						n.Li(M{"id": "todo-1"}, Text("one"))
+						n.Li(nil, Text("two"))
+					})
+					n.P(nil, Part("count", Text("2 items")))
+				})
+				n.Footer(nil, func(n *Node) {
+					*footers++
+				})
+			})
+		})
+	}
+}
+
+func TestTarget(t *testing.T) {
+	testCases := []targetTestCase{
+		{target: "", expected: `<html><body><h1>Todos</h1><main><ul id="list"><li id="todo-1">one</li><li>two</li></ul><p>2 items</p></main><footer></footer></body></html>`},
+		{target: "list", expected: `<ul id="list"><li id="todo-1">one</li><li>two</li></ul>`},
+		{target: "todo-1", expected: `<li id="todo-1">one</li>`},
+		{target: "count", expected: `2 items`},
+		{target: "list", devMode: true, expected: "<ul id=\"list\">\n  <li id=\"todo-1\">one</li>\n  <li>two</li>\n</ul>\n"},
+		{target: "list", minify: true, expected: `<ul id=list><li id=todo-1>one<li>two</ul>`},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("renders '%v' (dev mode: %v, minify: %v)", tc.target, tc.devMode, tc.minify)
+		t.Run(name, func(t *testing.T) {
+			b := &strings.Builder{}
+			n := &Node{Writer: b, Target: tc.target, DevMode: tc.devMode, Minify: tc.minify}
+			footers := 0
+			targetPage(&footers)(n)
+			assert.Equal(t, tc.expected, b.String())
+			assert.NoError(t, Error(n))
+		})
+	}
+
+	t.Run("skips the rest of the page", func(t *testing.T) {
+		footers := 0
+		targetPage(&footers)(&Node{Writer: &strings.Builder{}, Target: "list"})
+		assert.Equal(t, 0, footers)
+	})
+
+	t.Run("selects elements wrapped by WrapEach", func(t *testing.T) {
+		for _, tc := range []targetTestCase{
+			{target: "list", expected: "<ul id=\"list\"><li><p id=\"a\">a</p></li> \n <li><p>b</p></li></ul>"},
+			{target: "a", expected: `<p id="a">a</p>`},
+			{target: "list", minify: true, expected: `<ul id=list><li><p id=a>a</li> <li><p>b</ul>`},
+		} {
+			b := &strings.Builder{}
+			n := &Node{Writer: b, Target: tc.target, Minify: tc.minify}
+			n.Main(nil, func(n *Node) {
+				n.Ul(M{"id": "list"}, func(n *Node) {
+					WrapEach(n, func(n *Node, content func(*Node)) { n.Li(nil, content) }, func(n *Node) {
+						n.P(M{"id": "a"}, Text("a"))
+						Text(" \n ")(n)
+						n.P(nil, Text("b"))
+					})
+				})
+				n.P(nil, Text("after"))
+			})
+			assert.Equal(t, tc.expected, b.String())
+			assert.NoError(t, Error(n))
+		}
+	})
+
+	t.Run("reports missing targets", func(t *testing.T) {
+		b := &strings.Builder{}
+		n := &Node{Writer: b, Target: "missing"}
+		footers := 0
+		targetPage(&footers)(n)
+		assert.Empty(t, b.String())
+		assert.Equal(t, 1, footers)
+		assert.ErrorIs(t, Error(n), ErrTargetNotFound)
+	})
+}
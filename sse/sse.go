@@ -0,0 +1,146 @@
+// Package sse streams rendered mx fragments as Server-Sent Events, in the
+// format read by EventSource and the HTMX SSE extension:
+//
+//	func notifications(w http.ResponseWriter, r *http.Request) {
+//		s, err := sse.NewStream(w)
+//		if err != nil {
+//			http.Error(w, err.Error(), http.StatusInternalServerError)
+//			return
+//		}
+//		for {
+//			select {
+//			case <-r.Context().Done():
+//				return
+//			case msg := <-messages:
+//				// swapped by <div hx-ext="sse" sse-connect="/notifications" sse-swap="notification">
+//				if err := s.Render(sse.Event{Name: "notification", ID: msg.ID}, Notification(msg)); err != nil {
+//					return
+//				}
+//			}
+//		}
+//	}
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	// Stream writes events to a client.
+	Stream struct {
+		w  http.ResponseWriter
+		rc *http.ResponseController
+	}
+
+	// Event is the metadata of a message. All fields are optional.
+	Event struct {
+		Name  string        // event type, "message" if empty
+		ID    string        // sent back by the client in Last-Event-ID when reconnecting
+		Retry time.Duration // how long the client waits before reconnecting
+	}
+)
+
+// ErrInvalidField is returned when an event name or ID has a line break or,
+// for IDs, a NUL character, which the format can't represent.
+var ErrInvalidField = errors.New("sse: invalid character in event name or id")
+
+// NewStream starts an event stream on w. It fails if w can't be flushed,
+// before writing anything, so an error response can still be sent.
+func NewStream(w http.ResponseWriter) (*Stream, error) {
+	if !flushable(w) {
+		return nil, http.ErrNotSupported
+	}
+	s := &Stream{w: w, rc: http.NewResponseController(w)}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := s.rc.Flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LastEventID returns the ID of the last event received by a reconnecting
+// client, to resume the stream.
+func LastEventID(r *http.Request) string {
+	return r.Header.Get("Last-Event-ID")
+}
+
+// Send sends an event with data and flushes it. Each line of data is sent in a
+// data field, so the client receives it unchanged, with "\n" line breaks.
+func (s *Stream) Send(e Event, data string) error {
+	if strings.ContainsAny(e.Name, "\r\n") || strings.ContainsAny(e.ID, "\r\n\x00") {
+		return ErrInvalidField
+	}
+	b := &strings.Builder{}
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Name != "" {
+		b.WriteString("event: " + e.Name + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+	for line := range strings.SplitSeq(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// Render renders component and sends it as the data of an event. Nothing is
+// sent if rendering fails.
+func (s *Stream) Render(e Event, component func(*mx.Node)) error {
+	b := &strings.Builder{}
+	n := &mx.Node{Writer: b}
+	component(n)
+	if err := mx.Error(n); err != nil {
+		return err
+	}
+	return s.Send(e, b.String())
+}
+
+// Comment sends a comment, which clients ignore. Sending one periodically
+// keeps proxies from closing idle connections.
+func (s *Stream) Comment(text string) error {
+	b := &strings.Builder{}
+	for line := range strings.SplitSeq(strings.ReplaceAll(text, "\r", ""), "\n") {
+		b.WriteString(": " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// write writes a message and flushes it.
+func (s *Stream) write(msg string) error {
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// flushable checks if w, or a writer it wraps, can be flushed, following
+// Unwrap like http.ResponseController does.
+func flushable(w http.ResponseWriter) bool {
+	for {
+		switch t := w.(type) {
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return false
+		}
+	}
+}
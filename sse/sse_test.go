@@ -0,0 +1,110 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jlucasnsilva/mx"
+)
+
+type (
+	sendTestCase struct {
+		event    Event
+		data     string
+		expected string
+	}
+
+	// unflushable is a ResponseWriter without Flush.
+	unflushable struct {
+		http.ResponseWriter
+	}
+)
+
+func TestSend(t *testing.T) {
+	testCases := []sendTestCase{
+		{data: "hi", expected: "data: hi\n\n"},
+		{data: "", expected: "data: \n\n"},
+		{event: Event{Name: "update", ID: "42"}, data: "x", expected: "id: 42\nevent: update\ndata: x\n\n"},
+		{event: Event{Retry: 3 * time.Second}, data: "x", expected: "retry: 3000\ndata: x\n\n"},
+		{data: "a\nb\r\nc\rd\n", expected: "data: a\ndata: b\ndata: c\ndata: d\ndata: \n\n"},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("frames %q with %+v", tc.data, tc.event)
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s, err := NewStream(w)
+			assert.NoError(t, err)
+			assert.NoError(t, s.Send(tc.event, tc.data))
+			assert.Equal(t, tc.expected, w.Body.String())
+			assert.True(t, w.Flushed)
+		})
+	}
+
+	t.Run("rejects line breaks in fields", func(t *testing.T) {
+		s, _ := NewStream(httptest.NewRecorder())
+		assert.ErrorIs(t, s.Send(Event{Name: "a\nb"}, "x"), ErrInvalidField)
+		assert.ErrorIs(t, s.Send(Event{ID: "1\r"}, "x"), ErrInvalidField)
+		assert.ErrorIs(t, s.Send(Event{ID: "1\x00"}, "x"), ErrInvalidField)
+	})
+}
+
+func TestStream(t *testing.T) {
+	t.Run("sets headers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_, err := NewStream(w)
+		assert.NoError(t, err)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("requires flushing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_, err := NewStream(unflushable{w})
+		assert.ErrorIs(t, err, http.ErrNotSupported)
+		assert.Empty(t, w.Header().Get("Content-Type"))
+		assert.False(t, w.Flushed)
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("renders components", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s, _ := NewStream(w)
+		err := s.Render(Event{Name: "notification", ID: "7"}, func(n *mx.Node) {
+			n.Div(mx.S(`class="toast"`), mx.Text("line 1\nline 2"))
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "id: 7\nevent: notification\n"+
+			"data: <div class=\"toast\">line 1\n"+
+			"data: line 2</div>\n\n", w.Body.String())
+	})
+
+	t.Run("renders nested elements", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s, _ := NewStream(w)
+		assert.NoError(t, s.Render(Event{}, func(n *mx.Node) {
+			n.Ul(nil, func(n *mx.Node) { n.Li(nil, mx.Text("a")) })
+		}))
+		assert.Equal(t, "data: <ul><li>a</li></ul>\n\n", w.Body.String())
+	})
+
+	t.Run("sends comments", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s, _ := NewStream(w)
+		assert.NoError(t, s.Comment("ping\nagain"))
+		assert.Equal(t, ": ping\n: again\n\n", w.Body.String())
+	})
+
+	t.Run("reads the last event id", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Last-Event-ID", "41")
+		assert.Equal(t, "41", LastEventID(r))
+	})
+}
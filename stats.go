@@ -0,0 +1,89 @@
+package mx
+
+import (
+	"net/http"
+	"time"
+)
+
+type (
+	// RenderStats describes what a Node has rendered.
+	RenderStats struct {
+		Bytes    int64         // bytes written
+		Elements int           // elements rendered
+		MaxDepth int           // deepest element nesting
+		Duration time.Duration // from the first write to the end of Render, or to now
+		Flushes  int           // successful calls to Flush
+	}
+
+	// StatsObserver receives the stats of each page rendered with Render. It
+	// lets metrics and logging libraries (Prometheus, expvar, slog, ...) be
+	// plugged in without mx depending on them.
+	StatsObserver interface {
+		ObserveRender(RenderStats)
+	}
+
+	// StatsObserverFunc adapts a function to StatsObserver.
+	StatsObserverFunc func(RenderStats)
+
+	// flusher is implemented by buffered writers such as bufio.Writer.
+	flusher interface {
+		Flush() error
+	}
+)
+
+func (f StatsObserverFunc) ObserveRender(s RenderStats) {
+	f(s)
+}
+
+// Stats returns the render statistics of n so far.
+func Stats(n *Node) RenderStats {
+	s := n.stats
+	if s.Duration == 0 && !n.started.IsZero() {
+		s.Duration = time.Since(n.started)
+	}
+	return s
+}
+
+// Render renders component, reports its stats to n.Observer and returns the
+// write error, if any.
+func Render(n *Node, component func(*Node)) error {
+	start := time.Now()
+	if n.started.IsZero() {
+		n.started = start
+	}
+	component(n)
+	n.stats.Duration = time.Since(n.started)
+	if n.Observer != nil {
+		n.Observer.ObserveRender(n.stats)
+	}
+	return Error(n)
+}
+
+// Flush sends what has been rendered so far to the client, if the writer
+// supports flushing (http.Flusher, bufio.Writer, ...). Flushing after the
+// <head> lets browsers start fetching assets while the page is rendered.
+func Flush(n *Node) {
+	if n.err != nil {
+		return
+	}
+	n.flushEndTag("")
+	switch w := n.Writer.(type) {
+	case http.Flusher:
+		w.Flush()
+	case flusher:
+		if n.err = w.Flush(); n.err != nil {
+			return
+		}
+	default:
+		return
+	}
+	n.stats.Flushes++
+}
+
+// countElement updates the element stats when an element opens.
+func (n *Node) countElement() {
+	n.stats.Elements++
+	if depth := len(n.stack) + 1; depth > n.stats.MaxDepth {
+		n.stats.MaxDepth = depth
+	}
+}
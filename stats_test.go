@@ -0,0 +1,58 @@
+package mx
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("counts what is rendered", func(t *testing.T) {
+		b := &strings.Builder{}
+		n := &Node{Writer: b}
+		tracePage(n)
+		s := Stats(n)
+		assert.Equal(t, int64(b.Len()), s.Bytes)
+		assert.Equal(t, 4, s.Elements)
+		assert.Equal(t, 3, s.MaxDepth)
+		assert.Equal(t, 0, s.Flushes)
+		assert.Positive(t, s.Duration)
+	})
+
+	t.Run("reports to the observer", func(t *testing.T) {
+		var observed []RenderStats
+		n := &Node{
+			Writer: &strings.Builder{},
+			Observer: StatsObserverFunc(func(s RenderStats) {
+				observed = append(observed, s)
+			}),
+		}
+		assert.NoError(t, Render(n, tracePage))
+		assert.Len(t, observed, 1)
+		assert.Equal(t, Stats(n), observed[0])
+		assert.Equal(t, 4, observed[0].Elements)
+	})
+
+	t.Run("flushes", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		n := &Node{Writer: rec}
+		n.Head(nil)
+		Flush(n)
+		assert.True(t, rec.Flushed)
+
+		b := &strings.Builder{}
+		w := bufio.NewWriter(b)
+		n.Writer = w
+		n.Body(nil)
+		Flush(n)
+		assert.Equal(t, "<body></body>", b.String())
+		assert.Equal(t, 2, Stats(n).Flushes)
+
+		n.Writer = b
+		Flush(n)
+		assert.Equal(t, 2, Stats(n).Flushes)
+	})
+}
@@ -0,0 +1,73 @@
+package mx
+
+import (
+	"html"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// frame is an open element.
+type frame struct {
+	tag       string
+	component string     // function that rendered the element, when tracing
+	unnamed   *A11yIssue // reported if the element ends without an accessible name
+}
+
+// Node methods are skipped when looking for the component that rendered an element
+var nodeMethodPrefix = reflect.TypeOf(Node{}).PkgPath() + ".(*Node)."
+
+// Closure suffixes, e.g. ".func1" or ".func1.2"
+var closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+)+$`)
+
+// tracing checks if elements must be attributed to components.
+func (n *Node) tracing() bool {
+	return n.DevMode && n.Trace
+}
+
+// component returns the component of the innermost open element.
+func (n *Node) component() string {
+	if len(n.stack) == 0 {
+		return ""
+	}
+	return n.stack[len(n.stack)-1].component
+}
+
+// caller returns the name and file:line of the function that called a Node
+// method. Closures are attributed to the function that declares them, so the
+// children of an element declared inline belong to the same component.
+func caller() (name, source string) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, nodeMethodPrefix) {
+			return componentName(f.Function), f.File + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return "", ""
+		}
+	}
+}
+
+// componentName shortens a fully qualified function name, e.g.
+// "github.com/user/app/ui.Card.func1" becomes "ui.Card".
+func componentName(fn string) string {
+	if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+		fn = fn[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(fn, "")
+}
+
+// traceAttrs renders the attributes that annotate a component root.
+func traceAttrs(name, source string) string {
+	return `data-mx-component="` + html.EscapeString(name) + `" data-mx-source="` + html.EscapeString(source) + `"`
+}
+
+// traceComment renders a comment that marks a component boundary. Comments
+// can't contain "--".
+func traceComment(s string) string {
+	return "<!-- " + strings.ReplaceAll(s, "--", "- -") + " -->"
+}
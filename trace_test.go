@@ -0,0 +1,63 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func traceCard(n *Node) {
+	n.Div(S(`class="card"`), func(n *Node) {
+		n.P(nil, Text("body"))
+	})
+}
+
+func tracePage(n *Node) {
+	n.Main(nil, traceCard, func(n *Node) {
+		n.Hr(nil)
+	})
+}
+
+func TestComponentName(t *testing.T) {
+	testCases := map[string]string{
+		"github.com/user/app/ui.Card":         "ui.Card",
+		"github.com/user/app/ui.Card.func1":   "ui.Card",
+		"github.com/user/app/ui.Card.func1.2": "ui.Card",
+		"main.(*Page).Render.func3":           "main.(*Page).Render",
+		"ui.Funcs":                            "ui.Funcs",
+	}
+
+	for fn, expected := range testCases {
+		name := fmt.Sprintf("shortens '%v'", fn)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, componentName(fn))
+		})
+	}
+}
+
+func TestTrace(t *testing.T) {
+	t.Run("annotates component roots", func(t *testing.T) {
+		b := &strings.Builder{}
+		tracePage(&Node{Writer: b, DevMode: true, Trace: true})
+		assert.Regexp(t, `^<main data-mx-component="mx.tracePage" data-mx-source="[^"]*trace_test.go:18">\n`+
+			`  <div class="card" data-mx-component="mx.traceCard" data-mx-source="[^"]*trace_test.go:12">\n`+
+			`    <p>body</p>\n`+
+			`  </div>\n`+
+			`  <hr />\n`+
+			`</main>\n$`, b.String())
+	})
+
+	t.Run("marks component boundaries", func(t *testing.T) {
+		b := &strings.Builder{}
+		traceCard(&Node{Writer: b, DevMode: true, Trace: true, Markers: true})
+		assert.Regexp(t, `^<!-- mx.traceCard [^ ]*trace_test.go:12 --><div .*>\n  <p>body</p>\n</div><!-- /mx.traceCard -->\n$`, b.String())
+	})
+
+	t.Run("requires dev mode", func(t *testing.T) {
+		b := &strings.Builder{}
+		traceCard(&Node{Writer: b, Trace: true, Markers: true})
+		assert.Equal(t, `<div class="card"><p>body</p></div>`, b.String())
+	})
+}
@@ -0,0 +1,221 @@
+package mx
+
+import (
+	"html"
+	"slices"
+	"strings"
+)
+
+type (
+	// Kind is the kind of an Element.
+	Kind int
+
+	// Element is a node of the tree built by Build: an element, text, raw HTML
+	// or the fragment at the root.
+	Element struct {
+		Kind     Kind
+		Tag      string      // ElementKind: the tag name
+		Attrs    []Attribute // ElementKind: the attributes, in the order rendered
+		Text     string      // TextKind: the unescaped text
+		Raw      HTML        // RawKind: the markup
+		Children []*Element  // ElementKind and FragmentKind
+	}
+
+	// Attribute is an attribute of an Element, with its value unescaped.
+	// Attributes without a value, e.g. disabled, have an empty Value. URL
+	// values are checked against the scheme allowlist when rendered, like M
+	// does, unless they are trusted.
+	Attribute struct {
+		Name    string
+		Value   string
+		Trusted bool // Value is a TrustedURL, e.g. set with U, written without the scheme check
+	}
+
+	// TreeBuilder is a Backend that builds a tree.
+	TreeBuilder struct {
+		stack []*Element // the open elements, starting at the root fragment
+	}
+)
+
+const (
+	FragmentKind Kind = iota // top-level nodes rendered by a component
+	ElementKind
+	TextKind
+	RawKind
+)
+
+// Build renders component into a tree instead of writing it, so the output can
+// be inspected or changed before it is written with Element.Render.
+func Build(component func(*Node)) (*Element, error) {
+	b := NewTreeBuilder()
+	n := &Node{Backend: b}
+	component(n)
+	if err := Error(n); err != nil {
+		return nil, err
+	}
+	return b.Root(), nil
+}
+
+// NewTreeBuilder returns a TreeBuilder with an empty tree.
+func NewTreeBuilder() *TreeBuilder {
+	return &TreeBuilder{stack: []*Element{{Kind: FragmentKind}}}
+}
+
+// Root returns the fragment at the root of the tree.
+func (b *TreeBuilder) Root() *Element {
+	return b.stack[0]
+}
+
+// Render renders the tree as a component, so the settings of n, e.g. DevMode or
+// Minify, apply.
+func (e *Element) Render(n *Node) {
+	switch e.Kind {
+	case ElementKind:
+		children := make([]func(*Node), len(e.Children))
+		for i, c := range e.Children {
+			children[i] = c.Render
+		}
+		n.el(e.Tag, attrString(sanitizeAttrs(e.Attrs)), children...)
+	case TextKind:
+		Text(e.Text)(n)
+	case RawKind:
+		Raw(e.Raw)(n)
+	case FragmentKind:
+		for _, c := range e.Children {
+			c.Render(n)
+		}
+	}
+}
+
+// Walk calls fn for e and each of its descendants, in document order.
+func (e *Element) Walk(fn func(*Element)) {
+	fn(e)
+	for _, c := range e.Children {
+		c.Walk(fn)
+	}
+}
+
+// Find returns the elements with tag in e's subtree, in document order.
+func (e *Element) Find(tag string) []*Element {
+	var found []*Element
+	e.Walk(func(c *Element) {
+		if c.Kind == ElementKind && c.Tag == tag {
+			found = append(found, c)
+		}
+	})
+	return found
+}
+
+// TextContent returns the text of e's subtree. Raw HTML isn't included.
+func (e *Element) TextContent() string {
+	b := &strings.Builder{}
+	e.Walk(func(c *Element) {
+		if c.Kind == TextKind {
+			b.WriteString(c.Text)
+		}
+	})
+	return b.String()
+}
+
+// Attr returns the value of an attribute, and whether e has it.
+func (e *Element) Attr(name string) (string, bool) {
+	for _, a := range e.Attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttr sets the value of an attribute, adding it if e doesn't have it. The
+// value isn't trusted: URLs are checked when e is rendered.
+func (e *Element) SetAttr(name, value string) {
+	for i, a := range e.Attrs {
+		if a.Name == name {
+			e.Attrs[i] = Attribute{Name: name, Value: value}
+			return
+		}
+	}
+	e.Attrs = append(e.Attrs, Attribute{Name: name, Value: value})
+}
+
+// RemoveAttr removes an attribute.
+func (e *Element) RemoveAttr(name string) {
+	for i, a := range e.Attrs {
+		if a.Name == name {
+			e.Attrs = append(e.Attrs[:i], e.Attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+// parseAttributes parses a rendered attribute string.
+func parseAttributes(s string) []Attribute {
+	var attrs []Attribute
+	for _, a := range parseAttrs(s) {
+		attrs = append(attrs, Attribute{Name: a.name, Value: html.UnescapeString(a.value)})
+	}
+	return attrs
+}
+
+// sanitizeAttrs returns attrs with the URLs that aren't trusted sanitized like
+// M does.
+func sanitizeAttrs(attrs []Attribute) []Attribute {
+	safe := slices.Clone(attrs)
+	for i, a := range safe {
+		if isURLAttr(a.Name) && !a.Trusted {
+			safe[i].Value = sanitizeURL(a.Value)
+		}
+	}
+	return safe
+}
+
+// attrString renders attributes like M does, but in order and without
+// sanitizing URLs, which callers do with sanitizeAttrs.
+func attrString(attrs []Attribute) S {
+	b := &strings.Builder{}
+	for i, a := range attrs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.Name)
+		if !isVoidAttr(a.Name) || a.Value != "" {
+			b.WriteString(`="` + html.EscapeString(a.Value) + `"`)
+		}
+	}
+	return S(b.String())
+}
+
+func (b *TreeBuilder) OpenTag(tag string, attrs []Attribute) error {
+	e := &Element{Kind: ElementKind, Tag: tag, Attrs: slices.Clone(attrs)}
+	b.add(e)
+	b.stack = append(b.stack, e)
+	return nil
+}
+
+// Text adds text, merged with the text before it.
+func (b *TreeBuilder) Text(text string) error {
+	children := b.stack[len(b.stack)-1].Children
+	if k := len(children); k > 0 && children[k-1].Kind == TextKind {
+		children[k-1].Text += text
+		return nil
+	}
+	b.add(&Element{Kind: TextKind, Text: text})
+	return nil
+}
+
+func (b *TreeBuilder) Raw(raw HTML) error {
+	b.add(&Element{Kind: RawKind, Raw: raw})
+	return nil
+}
+
+func (b *TreeBuilder) CloseTag(tag string) error {
+	b.stack = b.stack[:len(b.stack)-1]
+	return nil
+}
+
+// add adds a node to the innermost open element.
+func (b *TreeBuilder) add(e *Element) {
+	parent := b.stack[len(b.stack)-1]
+	parent.Children = append(parent.Children, e)
+}
@@ -0,0 +1,142 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	treeTestCase struct {
+		component func(*Node)
+		expected  *Element
+	}
+)
+
+func treeGallery(n *Node) {
+	n.Section(M{"id": "gallery"}, func(n *Node) {
+		n.H2(nil, Text("Photos"), Text(" & more"))
+		n.Img(S(`src="/a.png" alt="A &amp; B"`))
+		n.Img(S(`src="/b.png" alt="" disabled`))
+		n.P(nil, Raw("<em>raw</em>"))
+	})
+}
+
+func TestBuild(t *testing.T) {
+	testCases := []treeTestCase{
+		{
+			component: Text("hello"),
+			expected: &Element{Kind: FragmentKind, Children: []*Element{
+				{Kind: TextKind, Text: "hello"},
+			}},
+		},
+		{
+			component: treeGallery,
+			expected: &Element{Kind: FragmentKind, Children: []*Element{
+				{Kind: ElementKind, Tag: "section", Attrs: []Attribute{{Name: "id", Value: "gallery"}}, Children: []*Element{
+					{Kind: ElementKind, Tag: "h2", Children: []*Element{{Kind: TextKind, Text: "Photos & more"}}},
+					{Kind: ElementKind, Tag: "img", Attrs: []Attribute{{Name: "src", Value: "/a.png"}, {Name: "alt", Value: "A & B"}}},
+					{Kind: ElementKind, Tag: "img", Attrs: []Attribute{{Name: "src", Value: "/b.png"}, {Name: "alt"}, {Name: "disabled"}}},
+					{Kind: ElementKind, Tag: "p", Children: []*Element{{Kind: RawKind, Raw: "<em>raw</em>"}}},
+				}},
+			}},
+		},
+		{
+			component: func(n *Node) {
+				WrapEach(n, func(n *Node, content func(*Node)) { n.Li(nil, content) }, func(n *Node) {
+					n.Span(nil, Text("a"))
+					n.Span(nil, Text("b"))
+				})
+			},
+			expected: &Element{Kind: FragmentKind, Children: []*Element{
+				{Kind: ElementKind, Tag: "li", Children: []*Element{
+					{Kind: ElementKind, Tag: "span", Children: []*Element{{Kind: TextKind, Text: "a"}}},
+				}},
+				{Kind: ElementKind, Tag: "li", Children: []*Element{
+					{Kind: ElementKind, Tag: "span", Children: []*Element{{Kind: TextKind, Text: "b"}}},
+				}},
+			}},
+		},
+	}
+
+	for i, tc := range testCases {
+		name := fmt.Sprintf("builds the tree of component %d", i)
+		t.Run(name, func(t *testing.T) {
+			tree, err := Build(tc.component)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tree)
+		})
+	}
+}
+
+func TestElement(t *testing.T) {
+	t.Run("renders what was built", func(t *testing.T) {
+		tree, err := Build(treeGallery)
+		assert.NoError(t, err)
+
+		expected := &strings.Builder{}
+		treeGallery(&Node{Writer: expected})
+		b := &strings.Builder{}
+		tree.Render(&Node{Writer: b})
+		assert.Equal(t, expected.String(), b.String())
+	})
+
+	t.Run("renders changes", func(t *testing.T) {
+		tree, err := Build(treeGallery)
+		assert.NoError(t, err)
+
+		for _, img := range tree.Find("img") {
+			img.SetAttr("loading", "lazy")
+			img.RemoveAttr("disabled")
+		}
+		tree.Find("section")[0].SetAttr("id", `"photos"`)
+
+		b := &strings.Builder{}
+		tree.Render(&Node{Writer: b})
+		assert.Equal(t, `<section id="&#34;photos&#34;"><h2>Photos &amp; more</h2>`+
+			`<img src="/a.png" alt="A &amp; B" loading="lazy" /><img src="/b.png" alt="" loading="lazy" />`+
+			`<p><em>raw</em></p></section>`, b.String())
+	})
+
+	t.Run("checks URLs", func(t *testing.T) {
+		tree, err := Build(func(n *Node) {
+			n.A(M{"href": "/back"}, Text("Back"))
+			n.A(M{"href": "/next"}, Text("Next"))
+		})
+		assert.NoError(t, err)
+		tree.Find("a")[1].SetAttr("href", "javascript:alert(1)")
+
+		b := &strings.Builder{}
+		tree.Render(&Node{Writer: b})
+		assert.Equal(t, `<a href="/back">Back</a><a href="#ZgotmplZ">Next</a>`, b.String())
+	})
+
+	t.Run("queries the tree", func(t *testing.T) {
+		tree, err := Build(treeGallery)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "Photos & more", tree.TextContent())
+		assert.Len(t, tree.Find("img"), 2)
+		alt, ok := tree.Find("img")[1].Attr("alt")
+		assert.True(t, ok)
+		assert.Equal(t, "", alt)
+		_, ok = tree.Find("img")[0].Attr("disabled")
+		assert.False(t, ok)
+	})
+
+	t.Run("builds only the target", func(t *testing.T) {
+		tree, err := Build(func(n *Node) {
+			n.Target = "list"
+			n.Div(nil, func(n *Node) {
+				n.Ul(M{"id": "list"}, func(n *Node) { n.Li(nil, Text("one")) })
+				n.P(nil, Text("after"))
+			})
+		})
+		assert.NoError(t, err)
+		assert.Len(t, tree.Children, 1)
+		assert.Equal(t, "ul", tree.Children[0].Tag)
+		assert.Equal(t, "one", tree.TextContent())
+	})
+}
@@ -0,0 +1,83 @@
+package mx
+
+import (
+	"html"
+	"strings"
+)
+
+type (
+	// TrustedURL is a URL that was explicitly vetted by the caller. It is written
+	// as-is (though still escaped) and bypasses the scheme allowlist.
+	TrustedURL string
+
+	// U represents URL-valued attributes whose values are trusted.
+	U map[string]TrustedURL
+)
+
+// unsafeURL replaces URLs with disallowed schemes, matching html/template.
+const unsafeURL = "#ZgotmplZ"
+
+// URL-valued attributes that are filtered by sanitizeURL
+var urlAttrs = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"formaction": true,
+}
+
+// Schemes allowed in URL-valued attributes
+var urlSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+func (u U) Attributes() string {
+	var b strings.Builder
+	i := 0
+	for k, v := range u {
+		if k == "" {
+			continue
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k + `="` + html.EscapeString(string(v)) + `"`)
+		i++
+	}
+	return b.String()
+}
+
+// RegisterURLSchemes adds schemes to the allowlist used for URL-valued attributes.
+func RegisterURLSchemes(schemes ...string) {
+	for _, s := range schemes {
+		urlSchemes[strings.ToLower(s)] = true
+	}
+}
+
+// isURLAttr checks if an attribute holds a URL.
+func isURLAttr(attr string) bool {
+	return urlAttrs[strings.ToLower(attr)]
+}
+
+// sanitizeURL returns u if it is relative or its scheme is allowed, and
+// "#ZgotmplZ" otherwise.
+func sanitizeURL(u string) string {
+	if isSafeURL(u, urlSchemes) {
+		return u
+	}
+	return unsafeURL
+}
+
+// isSafeURL checks u against a scheme allowlist. Browsers ignore leading
+// spaces and control characters, and strip tabs and newlines anywhere, so
+// they're dropped before the scheme is extracted.
+func isSafeURL(u string, schemes map[string]bool) bool {
+	u = strings.TrimLeftFunc(u, func(r rune) bool { return r <= ' ' })
+	u = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(u)
+	i := strings.IndexAny(u, ":/?#")
+	if i < 0 || u[i] != ':' {
+		return true
+	}
+	return schemes[strings.ToLower(u[:i])]
+}
@@ -0,0 +1,64 @@
+package mx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	urlTestCase struct {
+		url      string
+		expected string
+	}
+)
+
+func TestSanitizeURL(t *testing.T) {
+	testCases := []urlTestCase{
+		{url: "https://example.com", expected: "https://example.com"},
+		{url: "http://example.com/a?b=c#d", expected: "http://example.com/a?b=c#d"},
+		{url: "mailto:someone@example.com", expected: "mailto:someone@example.com"},
+		{url: "/relative/path", expected: "/relative/path"},
+		{url: "relative?next=javascript:alert(1)", expected: "relative?next=javascript:alert(1)"},
+		{url: "#anchor", expected: "#anchor"},
+		{url: "", expected: ""},
+		{url: "javascript:alert(1)", expected: "#ZgotmplZ"},
+		{url: "JavaScript:alert(1)", expected: "#ZgotmplZ"},
+		{url: "  javascript:alert(1)", expected: "#ZgotmplZ"},
+		{url: "java\tscript:alert(1)", expected: "#ZgotmplZ"},
+		{url: "data:text/html,<script>", expected: "#ZgotmplZ"},
+		{url: "vbscript:msgbox", expected: "#ZgotmplZ"},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("sanitizes '%v'", tc.url)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sanitizeURL(tc.url))
+		})
+	}
+}
+
+func TestURLAttributes(t *testing.T) {
+	RegisterURLSchemes("TEL")
+	testCases := []simpleAttrTestCase{
+		{attr: M{"href": "javascript:alert(1)"}, expected: `href="#ZgotmplZ"`},
+		{attr: M{"src": "https://example.com/a.png"}, expected: `src="https://example.com/a.png"`},
+		{attr: M{"action": "/login"}, expected: `action="/login"`},
+		{attr: M{"formaction": "javascript:void(0)"}, expected: `formaction="#ZgotmplZ"`},
+		{attr: M{"HREF": "javascript:alert(1)"}, expected: `HREF="#ZgotmplZ"`},
+		{attr: M{"title": "javascript:alert(1)"}, expected: `title="javascript:alert(1)"`},
+		{attr: M{"href": "tel:+5511999999999"}, expected: `href="tel:+5511999999999"`},
+		{attr: N{"href": {"javascript:alert(1)": true}}, expected: `href="#ZgotmplZ"`},
+		{attr: U{"href": "javascript:alert(1)"}, expected: `href="javascript:alert(1)"`},
+		{attr: U{"href": `/a?b="c"`}, expected: `href="/a?b=&#34;c&#34;"`},
+		{attr: U{"": "/a"}, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("checks if '%T' filters URL attributes", tc.attr)
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.attr.Attributes())
+		})
+	}
+}
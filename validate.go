@@ -0,0 +1,169 @@
+package mx
+
+import (
+	"fmt"
+	"html"
+	"slices"
+)
+
+// Violation is an element that breaks the content model of the HTML spec.
+type Violation struct {
+	Tag       string // offending element
+	Parent    string // its parent element, or "" at the top level
+	Message   string
+	Component string // function that rendered the element
+	Source    string // file:line of the element in Component
+	Warning   bool   // browsers render it as written, but the markup is invalid
+}
+
+func (v *Violation) Error() string {
+	if v.Parent == "" {
+		return fmt.Sprintf("%s: <%s>: %s (%s)", v.Source, v.Tag, v.Message, v.Component)
+	}
+	return fmt.Sprintf("%s: <%s> in <%s>: %s (%s)", v.Source, v.Tag, v.Parent, v.Message, v.Component)
+}
+
+// Elements that only accept phrasing content
+var phrasingOnlyTags = map[string]bool{
+	"abbr": true, "b": true, "bdi": true, "bdo": true, "button": true,
+	"cite": true, "code": true, "data": true, "dfn": true, "em": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"i": true, "kbd": true, "label": true, "legend": true, "mark": true,
+	"meter": true, "output": true, "pre": true, "progress": true, "q": true,
+	"s": true, "samp": true, "small": true, "span": true, "strong": true,
+	"sub": true, "sup": true, "summary": true, "time": true, "u": true,
+	"var": true,
+}
+
+// Elements that bound the scope in which the parser looks for an open <p>
+var pScopeTags = map[string]bool{
+	"button": true, "caption": true, "html": true, "marquee": true,
+	"object": true, "table": true, "td": true, "template": true, "th": true,
+}
+
+// Elements that can't be nested in a button or a link
+var interactiveTags = map[string]bool{
+	"a": true, "button": true, "details": true, "embed": true,
+	"iframe": true, "input": true, "label": true, "select": true,
+	"textarea": true,
+}
+
+// Parents required by elements that are only valid in a specific context
+var requiredParents = map[string][]string{
+	"li":       {"ul", "ol", "menu"},
+	"dt":       {"dl", "div"},
+	"dd":       {"dl", "div"},
+	"td":       {"tr"},
+	"th":       {"tr"},
+	"thead":    {"table"},
+	"tbody":    {"table"},
+	"tfoot":    {"table"},
+	"caption":  {"table"},
+	"colgroup": {"table"},
+	"col":      {"colgroup"},
+	"optgroup": {"select"},
+	"option":   {"select", "datalist", "optgroup"},
+	"legend":   {"fieldset"},
+	"summary":  {"details"},
+	"source":   {"audio", "video", "picture"},
+	"track":    {"audio", "video"},
+}
+
+// Violations returns the content model violations found so far when
+// Node.Validate is set, including warnings.
+func Violations(n *Node) []*Violation {
+	return n.violations
+}
+
+// validating checks if elements must be validated.
+func (n *Node) validating() bool {
+	return n.DevMode && n.Validate
+}
+
+// validate checks an element being opened against its ancestors.
+func (n *Node) validate(f frame, attrs, source string) {
+	parent := n.parent()
+	report := func(warning bool, format string, args ...any) {
+		n.violations = append(n.violations, &Violation{
+			Tag:       f.tag,
+			Parent:    parent,
+			Message:   fmt.Sprintf(format, args...),
+			Component: f.component,
+			Source:    source,
+			Warning:   warning,
+		})
+	}
+
+	// Elements at the top level are fragments, e.g. a row rendered for an
+	// HTMX swap, whose parent isn't known.
+	if parents, ok := requiredParents[f.tag]; ok && parent != "" && parent != "template" && !slices.Contains(parents, parent) {
+		report(false, "must be a child of %s", list(parents))
+	}
+	switch {
+	case f.tag == "tr" && parent == "table":
+		report(true, "the parser wraps it in an implicit <tbody>")
+	case f.tag == "tr" && !slices.Contains([]string{"", "table", "thead", "tbody", "tfoot", "template"}, parent):
+		report(false, "must be a child of <table>, <thead>, <tbody> or <tfoot>")
+	}
+
+	if closesP[f.tag] {
+		if p := n.ancestor("p", pScopeTags); p {
+			report(false, "the parser closes the open <p> before it")
+		} else if phrasingOnlyTags[parent] {
+			report(true, "<%s> only accepts phrasing content", parent)
+		}
+	}
+	for _, container := range []string{"a", "form"} {
+		if f.tag == container && n.ancestor(container, nil) {
+			report(false, "<%s> elements can't be nested", container)
+		}
+	}
+	if interactiveTags[f.tag] && (n.ancestor("button", nil) || f.tag != "a" && n.ancestor("a", nil)) {
+		report(false, "interactive content can't be nested in <a> or <button>")
+	}
+
+	for _, a := range parseAttrs(attrs) {
+		if a.name != "id" {
+			continue
+		}
+		id := html.UnescapeString(a.value)
+		if first, ok := n.ids[id]; ok {
+			report(false, "duplicate id %q, first used at %s", id, first)
+		} else {
+			if n.ids == nil {
+				n.ids = map[string]string{}
+			}
+			n.ids[id] = source
+		}
+	}
+}
+
+// ancestor checks if an element with tag is open, looking no further than
+// the elements in scope.
+func (n *Node) ancestor(tag string, scope map[string]bool) bool {
+	for i := len(n.stack) - 1; i >= 0; i-- {
+		if n.stack[i].tag == tag {
+			return true
+		}
+		if scope[n.stack[i].tag] {
+			return false
+		}
+	}
+	return false
+}
+
+// list formats tags for messages, e.g. "<ul>, <ol> or <menu>".
+func list(tags []string) string {
+	s := ""
+	for i, t := range tags {
+		switch {
+		case i == 0:
+		case i == len(tags)-1:
+			s += " or "
+		default:
+			s += ", "
+		}
+		s += "<" + t + ">"
+	}
+	return s
+}
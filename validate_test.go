@@ -0,0 +1,165 @@
+package mx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	violationTestCase struct {
+		description string
+		component   func(*Node)
+		expected    []string // "tag in parent: message", warnings prefixed with "warning: "
+	}
+)
+
+func validateIDs(n *Node) {
+	n.Div(M{"id": "main"}, func(n *Node) {
+		n.Span(M{"id": "main"}, nil)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []violationTestCase{
+		{
+			description: "li outside a list",
+			component: func(n *Node) {
+				n.Div(nil, func(n *Node) { n.Li(nil, Text("item")) })
+			},
+			expected: []string{"li in div: must be a child of <ul>, <ol> or <menu>"},
+		},
+		{
+			description: "li in a list",
+			component: func(n *Node) {
+				n.Ul(nil, func(n *Node) { n.Li(nil, Text("item")) })
+			},
+			expected: []string{},
+		},
+		{
+			description: "div in p",
+			component: func(n *Node) {
+				n.P(nil, func(n *Node) {
+					n.Span(nil, func(n *Node) { n.Div(nil, Text("block")) })
+				})
+			},
+			expected: []string{"div in span: the parser closes the open <p> before it"},
+		},
+		{
+			description: "div in span",
+			component: func(n *Node) {
+				n.Span(nil, func(n *Node) { n.Div(nil, Text("block")) })
+			},
+			expected: []string{"warning: div in span: <span> only accepts phrasing content"},
+		},
+		{
+			description: "p in a table cell in p",
+			component: func(n *Node) {
+				n.Div(nil, func(n *Node) {
+					n.Table(nil, func(n *Node) {
+						n.TBody(nil, func(n *Node) {
+							n.Tr(nil, func(n *Node) { n.Td(nil, func(n *Node) { n.P(nil, nil) }) })
+						})
+					})
+				})
+			},
+			expected: []string{},
+		},
+		{
+			description: "tr in table",
+			component: func(n *Node) {
+				n.Table(nil, func(n *Node) {
+					n.Tr(nil, func(n *Node) { n.Td(nil, nil) })
+				})
+			},
+			expected: []string{"warning: tr in table: the parser wraps it in an implicit <tbody>"},
+		},
+		{
+			description: "nothing for top level fragments",
+			component: func(n *Node) {
+				n.Tr(nil, func(n *Node) { n.Td(nil, nil) })
+				n.Li(nil, nil)
+				n.Option(nil, nil)
+			},
+			expected: []string{},
+		},
+		{
+			description: "nested links",
+			component: func(n *Node) {
+				n.A(nil, func(n *Node) { n.A(nil, nil) })
+			},
+			expected: []string{"a in a: <a> elements can't be nested"},
+		},
+		{
+			description: "nested forms",
+			component: func(n *Node) {
+				n.Form(nil, func(n *Node) {
+					n.Div(nil, func(n *Node) { n.Form(nil, nil) })
+				})
+			},
+			expected: []string{"form in div: <form> elements can't be nested"},
+		},
+		{
+			description: "input in button",
+			component: func(n *Node) {
+				n.Button(nil, func(n *Node) { n.Input(nil) })
+			},
+			expected: []string{"input in button: interactive content can't be nested in <a> or <button>"},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := fmt.Sprintf("reports %v", tc.description)
+		t.Run(name, func(t *testing.T) {
+			n := &Node{Writer: &strings.Builder{}, DevMode: true, Validate: true}
+			tc.component(n)
+			violations := []string{}
+			for _, v := range Violations(n) {
+				s := fmt.Sprintf("%s in %s: %s", v.Tag, v.Parent, v.Message)
+				if v.Warning {
+					s = "warning: " + s
+				}
+				violations = append(violations, s)
+			}
+			assert.Equal(t, tc.expected, violations)
+		})
+	}
+
+	t.Run("reports duplicate ids with their source", func(t *testing.T) {
+		n := &Node{Writer: &strings.Builder{}, DevMode: true, Validate: true}
+		validateIDs(n)
+		violations := Violations(n)
+		if assert.Len(t, violations, 1) {
+			assert.Equal(t, "mx.validateIDs", violations[0].Component)
+			assert.Regexp(t, `validate_test.go:21$`, violations[0].Source)
+			assert.Regexp(t, `^duplicate id "main", first used at .*validate_test.go:20$`, violations[0].Message)
+		}
+	})
+
+	t.Run("joins errors but not warnings", func(t *testing.T) {
+		n := &Node{Writer: &strings.Builder{}, DevMode: true, Validate: true}
+		n.Table(nil, func(n *Node) {
+			n.Tr(nil, func(n *Node) { n.Li(nil, nil) })
+		})
+		err := Error(n)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "<li> in <tr>: must be a child of")
+		assert.NotContains(t, err.Error(), "implicit <tbody>")
+	})
+
+	t.Run("omits the parent at the top level", func(t *testing.T) {
+		v := &Violation{Tag: "div", Message: "duplicate id", Component: "main.page", Source: "page.go:3"}
+		assert.Equal(t, "page.go:3: <div>: duplicate id (main.page)", v.Error())
+		v.Parent = "body"
+		assert.Equal(t, "page.go:3: <div> in <body>: duplicate id (main.page)", v.Error())
+	})
+
+	t.Run("requires dev mode", func(t *testing.T) {
+		n := &Node{Writer: &strings.Builder{}, Validate: true}
+		validateIDs(n)
+		assert.Empty(t, Violations(n))
+		assert.NoError(t, Error(n))
+	})
+}